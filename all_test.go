@@ -1,8 +1,16 @@
 package sortutil
 
 import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -135,6 +143,132 @@ func TestReverseInterface(t *testing.T) {
 	}
 }
 
+type SortableByValid []Item
+
+func (s SortableByValid) Len() int {
+	return len(s)
+}
+
+func (s SortableByValid) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s SortableByValid) Less(i, j int) bool {
+	return !s[i].Valid && s[j].Valid
+}
+
+func TestSortStableReverse(t *testing.T) {
+	is := items()
+	SortStableReverse(SortableByValid(is))
+	// The valid items 6, 1, 9, 7, 4 come first, then the invalid items 3,
+	// 2, 8, 5, each in the reverse of their original order
+	c := []int64{4, 7, 9, 1, 6, 5, 8, 2, 3}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	// With enough elements for sort.Sort not to be stable, SortStableReverse
+	// still reverses the original order of ties exactly, while
+	// SortReverseInterface only guarantees the order of the keys
+	var ss, us []Item
+	for i := 0; i < 100; i++ {
+		ss = append(ss, Item{Id: int64(i), Valid: i%3 == 0})
+	}
+	us = append(us, ss...)
+	SortStableReverse(SortableByValid(ss))
+	var want []int64
+	for _, valid := range []bool{true, false} {
+		for i := 99; i >= 0; i-- {
+			if (i%3 == 0) == valid {
+				want = append(want, int64(i))
+			}
+		}
+	}
+	for i, v := range ss {
+		if v.Id != want[i] {
+			t.Errorf("ss[%d].Id is not %d, but %d", i, want[i], v.Id)
+		}
+	}
+	SortReverseInterface(SortableByValid(us))
+	seen := make(map[int64]bool)
+	for i, v := range us {
+		if v.Valid != ss[i].Valid {
+			t.Errorf("us[%d].Valid is not %v, but %v", i, ss[i].Valid, v.Valid)
+		}
+		seen[v.Id] = true
+	}
+	if len(seen) != len(us) {
+		t.Errorf("SortReverseInterface lost elements: %d distinct of %d", len(seen), len(us))
+	}
+}
+
+func TestDescendingStableTies(t *testing.T) {
+	is := items()
+	Sort(is, FieldGetter("Valid"), DescendingStableTies)
+	c := []int64{6, 1, 9, 7, 4, 3, 2, 8, 5}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	// The ties are in the same order as after a stable ascending sort
+	as := items()
+	sort.Stable(SortableByValid(as))
+	for i, v := range as[:4] {
+		if w := is[5+i]; v.Id != w.Id {
+			t.Errorf("is[%d].Id is not %d, but %d", 5+i, v.Id, w.Id)
+		}
+	}
+}
+
+func TestDescendingStableTiesEntryPoints(t *testing.T) {
+	if c := Compare(1, 2, DescendingStableTies); c != 1 {
+		t.Errorf("Compare(1, 2) is %d, not 1", c)
+	}
+	is := items()
+	if less := LessForField(is, "Id", DescendingStableTies); !less(0, 1) || less(1, 0) {
+		t.Error("LessForField doesn't sort Id 6 before Id 1")
+	}
+	MultiSort(is, KeySpec{Getter: FieldGetter("Valid"), Ordering: DescendingStableTies})
+	c := []int64{6, 1, 9, 7, 4, 3, 2, 8, 5}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("MultiSort: is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	if err := SortBySpecs(is, []SortSpec{{"Id", "DescendingStableTies"}}); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range is {
+		if v.Id != int64(9-i) {
+			t.Errorf("SortBySpecs: is[%d].Id is not %d, but %d", i, 9-i, v.Id)
+		}
+	}
+	ints := []int{3, 9, 1, 7, 5}
+	if top := TopN(ints, nil, DescendingStableTies, 3).([]int); !reflect.DeepEqual(top, []int{9, 7, 5}) {
+		t.Errorf("TopN is %v, not [9 7 5]", top)
+	}
+	if m := MergeSorted(DescendingStableTies, nil, []int{5, 3, 1}, []int{4, 2}).([]int); !reflect.DeepEqual(m, []int{5, 4, 3, 2, 1}) {
+		t.Errorf("MergeSorted is %v, not [5 4 3 2 1]", m)
+	}
+	b := NewBounded(2, nil, DescendingStableTies)
+	for _, v := range ints {
+		b.Push(v)
+	}
+	if kept := b.Slice().([]int); !reflect.DeepEqual(kept, []int{9, 7}) {
+		t.Errorf("BoundedSorter kept %v, not [9 7]", kept)
+	}
+	is = items()
+	New(is, FieldGetter("Valid"), DescendingStableTies).SortStableReverse()
+	c = []int64{3, 2, 8, 5, 6, 1, 9, 7, 4}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("SortStableReverse: is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
 func TestAscByFieldString(t *testing.T) {
 	is := items()
 	AscByField(is, "Name")
@@ -181,147 +315,3327 @@ func TestCiDescByFieldString(t *testing.T) {
 	}
 }
 
-func TestAscByFieldInt64(t *testing.T) {
+func TestAscByFieldInt64(t *testing.T) {
+	is := items()
+	AscByField(is, "Id")
+	for i, v := range is {
+		if v.Id != int64(i+1) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+		}
+	}
+}
+
+func TestDescByFieldInt64(t *testing.T) {
+	is := items()
+	DescByField(is, "Id")
+	l := len(is)
+	for i, v := range is {
+		if v.Id != int64(l-i) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, l-i, v.Id)
+		}
+	}
+}
+
+func TestLessForField(t *testing.T) {
+	is := items()
+	sort.Slice(is, LessForField(is, "Name", Ascending))
+	c := items()
+	AscByField(c, "Name")
+	for i, v := range is {
+		if v.Name != c[i].Name {
+			t.Errorf("is[%d].Name is not %s, but %s", i, c[i].Name, v.Name)
+		}
+	}
+}
+
+func TestLessForFieldPointers(t *testing.T) {
+	is := pointers()
+	sort.Slice(is, LessForField(is, "Date", Descending))
+	c := dates()
+	l := len(is)
+	for i, v := range is {
+		if !v.Date.Equal(c[l-i-1]) {
+			t.Errorf("is[%d].Date is not %v, but %v", i, c[l-i-1], v.Date)
+		}
+	}
+}
+
+func TestLessForFieldStable(t *testing.T) {
+	is := items()
+	sort.SliceStable(is, LessForField(is, "Valid", Descending))
+	c := []int64{6, 1, 9, 7, 4, 3, 2, 8, 5}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestAscByFieldWithOrder(t *testing.T) {
+	is := items()
+	AscByFieldWithOrder(is, "Name", []interface{}{"z", "a", "b", "C", "A", "y"})
+	c := []string{"z", "a", "b", "C", "A", "y"}
+	for i, v := range c {
+		if is[i].Name != v {
+			t.Errorf("is[%d].Name is not %s, but %s", i, v, is[i].Name)
+		}
+	}
+	rest := []string{is[6].Name, is[7].Name, is[8].Name}
+	sort.Strings(rest)
+	if !reflect.DeepEqual(rest, []string{"d", "g", "h"}) {
+		t.Errorf("The unknown names at the end are not d, g and h, but %v", rest)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	n := 5
+	cases := []struct {
+		a, b     interface{}
+		ordering Ordering
+		result   int
+	}{
+		{1, 2, Ascending, -1},
+		{1, 2, Descending, 1},
+		{int8(3), int8(3), Ascending, 0},
+		{uint(7), uint(2), Ascending, 1},
+		{1.5, 0.5, Descending, -1},
+		{math.NaN(), 0.5, Ascending, -1},
+		{"b", "a", Ascending, 1},
+		{"B", "a", Ascending, -1},
+		{"B", "a", CaseInsensitiveAscending, 1},
+		{"A", "a", CaseInsensitiveDescending, 0},
+		{false, true, Ascending, -1},
+		{false, true, Descending, 1},
+		{now, now.Add(day), Ascending, -1},
+		{now, now.Add(day), Descending, 1},
+		{now, now.UTC(), Ascending, 0},
+		{&n, 4, Ascending, 1},
+		{[]byte("a"), []byte("b"), Descending, 1},
+	}
+	for _, v := range cases {
+		if r := Compare(v.a, v.b, v.ordering); r != v.result {
+			t.Errorf("Compare(%v, %v, %v) is not %d, but %d", v.a, v.b, v.ordering, v.result, r)
+		}
+	}
+}
+
+type Person struct {
+	First string
+	Last  string
+	Age   int
+}
+
+func people() []Person {
+	return []Person{
+		{"ann", "Smith", 30},
+		{"Bob", "jones", 25},
+		{"carl", "smith", 41},
+		{"Dana", "Jones", 25},
+		{"eve", "Adams", 35},
+		{"Ann", "smith", 52},
+	}
+}
+
+func personNames(ps []Person) []string {
+	names := make([]string, len(ps))
+	for i, v := range ps {
+		names[i] = v.First + " " + v.Last
+	}
+	return names
+}
+
+func TestMultiSort(t *testing.T) {
+	ps := people()
+	MultiSort(ps,
+		KeySpec{Getter: FieldGetter("Age"), Ordering: Ascending},
+		KeySpec{Getter: FieldGetter("First"), Ordering: CaseInsensitiveDescending},
+	)
+	c := []string{"Dana Jones", "Bob jones", "ann Smith", "eve Adams", "carl smith", "Ann smith"}
+	if names := personNames(ps); !reflect.DeepEqual(names, c) {
+		t.Errorf("People were not sorted as %v, but %v", c, names)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	ps := people()
+	Build(ps).ByField("Last").CaseInsensitive().Descending().ByField("First").CaseInsensitive().Descending().Do()
+	es := people()
+	MultiSort(es,
+		KeySpec{Getter: FieldGetter("Last"), Ordering: CaseInsensitiveDescending},
+		KeySpec{Getter: FieldGetter("First"), Ordering: CaseInsensitiveDescending},
+	)
+	if !reflect.DeepEqual(ps, es) {
+		t.Errorf("Built sort gave %v, not %v", personNames(ps), personNames(es))
+	}
+	c := []string{"carl smith", "ann Smith", "Ann smith", "Dana Jones", "Bob jones", "eve Adams"}
+	if names := personNames(ps); !reflect.DeepEqual(names, c) {
+		t.Errorf("People were not sorted as %v, but %v", c, names)
+	}
+	ints := []int{2, 3, 1}
+	Build(ints).Descending().Do()
+	if !reflect.DeepEqual(ints, []int{3, 2, 1}) {
+		t.Errorf("Ints weren't sorted in descending order: %v", ints)
+	}
+}
+
+func TestAscByIndex(t *testing.T) {
+	is := nestedIntSlice()
+	AscByIndex(is, 2)
+	if !sort.IntsAreSorted([]int{is[0][2], is[1][2], is[2][2], is[3][2]}) {
+		t.Errorf("Nested int slice is not sorted by index 2 in child slices: %v", is)
+	}
+}
+
+// func TestAscIntArray(t *testing.T) {
+// 	ints := [...]int{4, 3, 1, 5, 2}
+// 	Asc(ints)
+// 	if !sort.IntsAreSorted(ints[:]) {
+// 		t.Errorf("Array ints weren't sorted: %v", ints)
+// 	}
+// }
+
+func TestAscByFieldTime(t *testing.T) {
+	is := items()
+	AscByField(is, "Date")
+	c := dates()
+	for i, v := range is {
+		if !v.Date.Equal(c[i]) {
+			t.Errorf("is[%d].Date is not %v, but %v", i, c[i], v.Date)
+		}
+	}
+}
+
+func TestDescByFieldTime(t *testing.T) {
+	is := items()
+	DescByField(is, "Date")
+	c := dates()
+	l := len(is)
+	for i, v := range is {
+		if !v.Date.Equal(c[l-i-1]) {
+			t.Errorf("is[%d].Date is not %v, but %v", i, c[l-i], v.Date)
+		}
+	}
+}
+
+func TestResolveField(t *testing.T) {
+	index, err := ResolveField(reflect.TypeOf(Item{}), "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	is := items()
+	AscByFieldIndex(is, index)
+	c := names()
+	for i, v := range is {
+		if v.Name != c[i] {
+			t.Errorf("is[%d].Name is not %s, but %s", i, c[i], v.Name)
+		}
+	}
+	if _, err = ResolveField(reflect.TypeOf(&Item{}), "Id"); err != nil {
+		t.Errorf("Resolving a field through a pointer type failed: %v", err)
+	}
+	if _, err = ResolveField(reflect.TypeOf(Item{}), "Missing"); err == nil {
+		t.Error("Resolving a missing field didn't return an error")
+	}
+	if _, err = ResolveField(reflect.TypeOf(TestStruct{}), "unexported"); err == nil {
+		t.Error("Resolving an unexported field didn't return an error")
+	}
+	if _, err = ResolveField(reflect.TypeOf(0), "Id"); err == nil {
+		t.Error("Resolving a field in a non-struct type didn't return an error")
+	}
+}
+
+func TestSortByStructField(t *testing.T) {
+	f, _ := reflect.TypeOf(Item{}).FieldByName("Id")
+	is := items()
+	SortByStructField(is, f, Descending)
+	l := len(is)
+	for i, v := range is {
+		if v.Id != int64(l-i) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, l-i, v.Id)
+		}
+	}
+}
+
+func byteSlices() [][]byte {
+	return [][]byte{[]byte("b"), []byte("C"), []byte("a"), []byte("Ab"), []byte("B")}
+}
+
+func TestAscByteSlices(t *testing.T) {
+	bs := byteSlices()
+	Asc(bs)
+	c := []string{"Ab", "B", "C", "a", "b"}
+	for i, v := range bs {
+		if string(v) != c[i] {
+			t.Errorf("bs[%d] is not %s, but %s", i, c[i], v)
+		}
+	}
+}
+
+func TestCiAscByteSlices(t *testing.T) {
+	bs := byteSlices()
+	CiAsc(bs)
+	c := []string{"a", "Ab", "b", "B", "C"}
+	for i, v := range bs {
+		if !bytes.EqualFold(v, []byte(c[i])) {
+			t.Errorf("bs[%d] is not %s, but %s", i, c[i], v)
+		}
+	}
+}
+
+func TestCiDescByteSlices(t *testing.T) {
+	bs := byteSlices()
+	CiDesc(bs)
+	c := []string{"C", "b", "B", "Ab", "a"}
+	for i, v := range bs {
+		if !bytes.EqualFold(v, []byte(c[i])) {
+			t.Errorf("bs[%d] is not %s, but %s", i, c[i], v)
+		}
+	}
+}
+
+func TestDescByteSlices(t *testing.T) {
+	bs := byteSlices()
+	Desc(bs)
+	c := []string{"b", "a", "C", "B", "Ab"}
+	for i, v := range bs {
+		if string(v) != c[i] {
+			t.Errorf("bs[%d] is not %s, but %s", i, c[i], v)
+		}
+	}
+}
+
+type Blob struct {
+	Id   int
+	Data []byte
+}
+
+func blobs() []Blob {
+	return []Blob{
+		{1, []byte("b")},
+		{2, []byte("C")},
+		{3, []byte("a")},
+		{4, []byte("Ab")},
+		{5, []byte("Ba")},
+	}
+}
+
+func TestDescByFieldBytes(t *testing.T) {
+	bs := blobs()
+	DescByField(bs, "Data")
+	c := []int{1, 3, 2, 5, 4}
+	for i, v := range bs {
+		if v.Id != c[i] {
+			t.Errorf("bs[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestCiDescByFieldBytes(t *testing.T) {
+	bs := blobs()
+	CiDescByField(bs, "Data")
+	c := []int{2, 5, 1, 4, 3}
+	for i, v := range bs {
+		if v.Id != c[i] {
+			t.Errorf("bs[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestSortByFrequencySlices(t *testing.T) {
+	ls := []Line{{1, []rune("b")}, {2, []rune("a")}, {3, []rune("b")}, {4, []rune("c")}, {5, []rune("b")}, {6, []rune("a")}}
+	SortByFrequency(ls, FieldGetter("Text"), Descending)
+	c := []int{1, 3, 5, 2, 6, 4}
+	for i, v := range ls {
+		if v.Number != c[i] {
+			t.Errorf("ls[%d].Number is not %d, but %d", i, c[i], v.Number)
+		}
+	}
+	rs := []Release{{"a", []int{1, 2}}, {"b", []int{2}}, {"c", []int{1, 2}}, {"d", []int{1, 2, 0}}}
+	SortByFrequency(rs, FieldGetter("Version"), Descending)
+	if rs[0].Name != "a" || rs[1].Name != "c" {
+		t.Errorf("Releases sorted by frequency of Version: %v", rs)
+	}
+}
+
+func TestAscByFieldWithOrderSlices(t *testing.T) {
+	ls := lines()
+	AscByFieldWithOrder(ls, "Text", []interface{}{[]rune("apple"), []rune("étude")})
+	if ls[0].Number != 3 || ls[1].Number != 1 {
+		t.Errorf("Lines sorted by Text with order: %v", ls)
+	}
+	rs := []Release{{"a", []int{1, 2}}, {"b", []int{2}}, {"c", []int{0, 9}}}
+	AscByFieldWithOrder(rs, "Version", []interface{}{[]int{0, 9}, []int{2}})
+	if rs[0].Name != "c" || rs[1].Name != "b" || rs[2].Name != "a" {
+		t.Errorf("Releases sorted by Version with order: %v", rs)
+	}
+}
+
+func TestSortByFrequency(t *testing.T) {
+	ss := []string{"b", "c", "a", "c", "d", "b", "c", "a", "e"}
+	SortByFrequency(ss, nil, Descending)
+	c := []string{"c", "c", "c", "a", "a", "b", "b", "d", "e"}
+	if !reflect.DeepEqual(ss, c) {
+		t.Errorf("Slice sorted by descending frequency is not %v, but %v", c, ss)
+	}
+	ss = []string{"b", "c", "a", "c", "d", "b", "c", "a", "e"}
+	SortByFrequency(ss, nil, Ascending)
+	c = []string{"d", "e", "a", "a", "b", "b", "c", "c", "c"}
+	if !reflect.DeepEqual(ss, c) {
+		t.Errorf("Slice sorted by ascending frequency is not %v, but %v", c, ss)
+	}
+}
+
+func TestSortByFrequencyNaN(t *testing.T) {
+	nan := math.NaN()
+	fs := []float64{1, nan, 2, 1, nan}
+	SortByFrequency(fs, nil, Descending)
+	if !math.IsNaN(fs[0]) || !math.IsNaN(fs[1]) || fs[2] != 1 || fs[3] != 1 || fs[4] != 2 {
+		t.Errorf("Floats sorted by descending frequency: %v", fs)
+	}
+	a, b := complex(nan, 1), complex(nan, 1)
+	if mapKey(reflect.ValueOf(a)) != mapKey(reflect.ValueOf(b)) {
+		t.Errorf("Complex numbers with NaN parts have different keys")
+	}
+	if mapKey(reflect.ValueOf(a)) == mapKey(reflect.ValueOf(complex(1, nan))) {
+		t.Errorf("Complex numbers with NaN in different parts have the same key")
+	}
+	ms := []Measurement{{1, 2}, {2, nan}, {3, 1}}
+	Sort(ms, RankGetter(FieldGetter("Value"), []interface{}{nan, 1.0}), Ascending)
+	if ms[0].Id != 2 || ms[1].Id != 3 || ms[2].Id != 1 {
+		t.Errorf("Measurements sorted by rank with NaN first: %v", ms)
+	}
+	ms = []Measurement{{1, nan}, {2, 1}, {3, nan}}
+	o := CaptureOrder(ms, FieldGetter("Value"))
+	ms[0], ms[2] = ms[2], ms[0]
+	AscByField(ms, "Id")
+	RestoreOrder(ms, o)
+	if ms[0].Id != 1 || ms[1].Id != 2 || ms[2].Id != 3 {
+		t.Errorf("Restored measurements with NaN values: %v", ms)
+	}
+}
+
+func TestSortedKeysByCount(t *testing.T) {
+	counts := make(map[string]int)
+	for _, w := range strings.Fields("the cat and the dog and the bird saw a cat") {
+		counts[w]++
+	}
+	keys := SortedKeysByCount(counts)
+	c := []interface{}{"the", "and", "cat", "a", "bird", "dog", "saw"}
+	if !reflect.DeepEqual(keys, c) {
+		t.Errorf("Keys are %v, not %v", keys, c)
+	}
+	if keys := SortedKeysByCount(map[int]uint{}); len(keys) != 0 {
+		t.Errorf("Keys of an empty map are %v", keys)
+	}
+}
+
+func TestSortByFrequencyStable(t *testing.T) {
+	is := items()
+	SortByFrequency(is, FieldGetter("Valid"), Descending)
+	// Five items are valid, and four aren't
+	c := []int64{6, 1, 9, 7, 4, 3, 2, 8, 5}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+type Line struct {
+	Number int
+	Text   []rune
+}
+
+func lines() []Line {
+	return []Line{
+		{1, []rune("étude")},
+		{2, []rune("Zebra")},
+		{3, []rune("apple")},
+		{4, []rune("Étage")},
+		{5, []rune("app")},
+	}
+}
+
+func TestAscMixedIntegers(t *testing.T) {
+	xs := []interface{}{uint64(math.MaxUint64), int8(-3), uint8(7), int64(math.MinInt64), 5, uint(0)}
+	Asc(xs)
+	correct := []interface{}{int64(math.MinInt64), int8(-3), uint(0), 5, uint8(7), uint64(math.MaxUint64)}
+	if !reflect.DeepEqual(xs, correct) {
+		t.Errorf("Sorted slice was not %v: %v", correct, xs)
+	}
+	Desc(xs)
+	for i, v := range xs {
+		if c := correct[len(xs)-i-1]; v != c {
+			t.Errorf("xs[%d] is not %v, but %v", i, c, v)
+		}
+	}
+}
+
+func TestAscByFieldRunes(t *testing.T) {
+	ls := lines()
+	AscByField(ls, "Text")
+	c := []int{2, 5, 3, 4, 1}
+	for i, v := range ls {
+		if v.Number != c[i] {
+			t.Errorf("ls[%d].Number is not %d, but %d", i, c[i], v.Number)
+		}
+	}
+}
+
+func TestCiAscByFieldRunes(t *testing.T) {
+	ls := lines()
+	CiAscByField(ls, "Text")
+	c := []int{5, 3, 2, 4, 1}
+	for i, v := range ls {
+		if v.Number != c[i] {
+			t.Errorf("ls[%d].Number is not %d, but %d", i, c[i], v.Number)
+		}
+	}
+}
+
+func TestDescByFieldRunes(t *testing.T) {
+	ls := lines()
+	DescByField(ls, "Text")
+	c := []int{1, 4, 3, 5, 2}
+	for i, v := range ls {
+		if v.Number != c[i] {
+			t.Errorf("ls[%d].Number is not %d, but %d", i, c[i], v.Number)
+		}
+	}
+}
+
+func TestCiDescByFieldRunes(t *testing.T) {
+	ls := lines()
+	CiDescByField(ls, "Text")
+	c := []int{1, 4, 2, 3, 5}
+	for i, v := range ls {
+		if v.Number != c[i] {
+			t.Errorf("ls[%d].Number is not %d, but %d", i, c[i], v.Number)
+		}
+	}
+}
+
+type Release struct {
+	Name    string
+	Version []int
+}
+
+func TestAscByFieldIntSlice(t *testing.T) {
+	rs := []Release{
+		{"d", []int{2, 0}},
+		{"b", []int{1, 2}},
+		{"c", []int{1, 10}},
+		{"a", []int{1}},
+		{"e", []int{10}},
+	}
+	AscByField(rs, "Version")
+	c := []string{"a", "b", "c", "d", "e"}
+	for i, v := range rs {
+		if v.Name != c[i] {
+			t.Errorf("rs[%d].Name is not %s, but %s", i, c[i], v.Name)
+		}
+	}
+	DescByField(rs, "Version")
+	for i, v := range rs {
+		if v.Name != c[len(c)-i-1] {
+			t.Errorf("rs[%d].Name is not %s, but %s", i, c[len(c)-i-1], v.Name)
+		}
+	}
+}
+
+func TestAscStringSlices(t *testing.T) {
+	ss := [][]string{{"b"}, {"a", "z"}, {"a"}, {"a", "b"}}
+	Asc(ss)
+	correct := [][]string{{"a"}, {"a", "b"}, {"a", "z"}, {"b"}}
+	if !reflect.DeepEqual(ss, correct) {
+		t.Errorf("Sorted slice was not %v: %v", correct, ss)
+	}
+}
+
+type TaggedStruct struct {
+	Id      int    `xml:"id,attr" db:"key"`
+	Comment string `xml:",chardata" db:"-"`
+	Title   string `json:"title,omitempty" xml:"title" db:"name"`
+}
+
+func taggedStructs() []TaggedStruct {
+	return []TaggedStruct{
+		{2, "b", "z"},
+		{3, "a", "x"},
+		{1, "c", "y"},
+	}
+}
+
+func TestTagGetter(t *testing.T) {
+	cases := []struct {
+		getter Getter
+		ids    []int
+	}{
+		{XMLTagGetter("id"), []int{1, 2, 3}},
+		{XMLTagGetter("Comment"), []int{3, 2, 1}},
+		{XMLTagGetter("title"), []int{3, 1, 2}},
+		{JSONTagGetter("title"), []int{3, 1, 2}},
+		{JSONTagGetter("Id"), []int{1, 2, 3}},
+		{TagGetter("db", "key"), []int{1, 2, 3}},
+		{TagGetter("db", "name"), []int{3, 1, 2}},
+	}
+	for i, v := range cases {
+		ts := taggedStructs()
+		Sort(ts, v.getter, Ascending)
+		for j, w := range ts {
+			if w.Id != v.ids[j] {
+				t.Errorf("Case %d: ts[%d].Id is not %d, but %d", i, j, v.ids[j], w.Id)
+			}
+		}
+	}
+}
+
+func TestTagGetterMissing(t *testing.T) {
+	defer func() {
+		if x := recover(); x == nil {
+			t.Fatal("Sorting by a field tagged \"-\" didn't cause a panic")
+		}
+	}()
+	Sort(taggedStructs(), TagGetter("db", "Comment"), Ascending)
+}
+
+type Owner struct {
+	Name  string
+	Roles []string
+}
+
+type Document struct {
+	Id    int
+	Owner *Owner
+	Tags  []string
+}
+
+func TestPathGetter(t *testing.T) {
+	ds := []Document{
+		{1, &Owner{"x", []string{"editor", "admin"}}, []string{"b"}},
+		{2, &Owner{"y", []string{"admin"}}, []string{"c"}},
+		{3, &Owner{"z", []string{"viewer"}}, []string{"a"}},
+	}
+	Sort(ds, MustPathGetter("Owner.Roles[0]"), Ascending)
+	c := []int{2, 1, 3}
+	for i, v := range ds {
+		if v.Id != c[i] {
+			t.Errorf("ds[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	Sort(ds, MustPathGetter("Tags[0]"), Descending)
+	c = []int{2, 1, 3}
+	for i, v := range ds {
+		if v.Id != c[i] {
+			t.Errorf("ds[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	is := nestedIntSlice()
+	Sort(is, MustPathGetter("[1]"), Ascending)
+	if !sort.IntsAreSorted([]int{is[0][1], is[1][1], is[2][1], is[3][1]}) {
+		t.Errorf("Nested int slice is not sorted by index 1 in child slices: %v", is)
+	}
+}
+
+func TestPathGetterInvalid(t *testing.T) {
+	for _, path := range []string{"", "Owner.", "Owner..Name", "Tags[", "Tags[x]", "Tags[-1]", "Tags]", "Tags[0]Name"} {
+		if _, err := PathGetter(path); err == nil {
+			t.Errorf("Parsing the invalid path %q didn't return an error", path)
+		}
+	}
+}
+
+func TestPathGetterOutOfRange(t *testing.T) {
+	defer func() {
+		if x := recover(); x == nil {
+			t.Fatal("Sorting by an index out of range didn't cause a panic")
+		}
+	}()
+	ds := []Document{{Id: 1, Tags: []string{"a"}}, {Id: 2}}
+	Sort(ds, MustPathGetter("Tags[0]"), Ascending)
+}
+
+func TestAscErrors(t *testing.T) {
+	es := []error{errors.New("c"), nil, errors.New("a"), errors.New("b")}
+	Sort(es, ErrorGetter(""), Ascending)
+	if es[0] != nil {
+		t.Errorf("es[0] is not nil, but %v", es[0])
+	}
+	for i, v := range es[1:] {
+		if c := string(rune('a' + i)); v.Error() != c {
+			t.Errorf("es[%d] is not %s, but %v", i+1, c, v)
+		}
+	}
+	s := New(es, ErrorGetter(""), Descending)
+	s.Policy.Nils = Last
+	s.Sort()
+	if es[3] != nil || es[0].Error() != "c" {
+		t.Errorf("Errors weren't sorted in descending order with nils last: %v", es)
+	}
+}
+
+type Failure struct {
+	Id  int
+	Err error
+}
+
+func TestAscByErrorField(t *testing.T) {
+	fs := []Failure{{1, errors.New("timeout")}, {2, errors.New("refused")}, {3, nil}}
+	Sort(fs, ErrorGetter("Err"), Descending)
+	c := []int{1, 2, 3}
+	for i, v := range fs {
+		if v.Id != c[i] {
+			t.Errorf("fs[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func table() [][]string {
+	return [][]string{
+		{"name", "qty", "note"},
+		{"pear", "10"},
+		{"apple", "2", "ok"},
+		{"fig", "x", "bad"},
+		{"date", "33", "ok"},
+	}
+}
+
+func TestSortTableByColumn(t *testing.T) {
+	rows := table()
+	if err := SortTableByColumn(rows, "name", Ascending); err != nil {
+		t.Fatal(err)
+	}
+	c := []string{"name", "apple", "date", "fig", "pear"}
+	for i, v := range rows {
+		if v[0] != c[i] {
+			t.Errorf("rows[%d][0] is not %s, but %s", i, c[i], v[0])
+		}
+	}
+	if err := SortTableByColumn(rows, "qty", Ascending); err != nil {
+		t.Fatal(err)
+	}
+	c = []string{"qty", "10", "2", "33", "x"}
+	for i, v := range rows {
+		if v[1] != c[i] {
+			t.Errorf("rows[%d][1] is not %s, but %s", i, c[i], v[1])
+		}
+	}
+	if err := SortTableByColumnNumeric(rows, "qty", Descending); err != nil {
+		t.Fatal(err)
+	}
+	c = []string{"qty", "33", "10", "2", "x"}
+	for i, v := range rows {
+		if v[1] != c[i] {
+			t.Errorf("rows[%d][1] is not %s, but %s", i, c[i], v[1])
+		}
+	}
+	if err := SortTableByColumn(rows, "note", Descending); err != nil {
+		t.Fatal(err)
+	}
+	if rows[4][0] != "pear" {
+		t.Errorf("The row without a note is not last, but %v", rows[4])
+	}
+	if err := SortTableByColumn(rows, "price", Ascending); err == nil {
+		t.Error("Sorting by a missing column didn't return an error")
+	}
+}
+
+func TestHashGetter(t *testing.T) {
+	ids := func(seed uint64) []int64 {
+		is := items()
+		Sort(is, HashGetter("Name", seed), Ascending)
+		res := make([]int64, len(is))
+		for i, v := range is {
+			res[i] = v.Id
+		}
+		return res
+	}
+	a, b := ids(1), ids(1)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Orders for the same seed differ: %v and %v", a, b)
+	}
+	c := ids(2)
+	if reflect.DeepEqual(a, c) {
+		t.Errorf("Orders for different seeds are the same: %v", a)
+	}
+	sorted := append([]int64(nil), a...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if reflect.DeepEqual(a, sorted) {
+		t.Errorf("Order for seed 1 is not scrambled: %v", a)
+	}
+}
+
+type Link struct {
+	Id  int
+	URL *url.URL
+}
+
+func mustParseURL(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func TestAscByFieldURL(t *testing.T) {
+	ls := []Link{
+		{1, mustParseURL("https://b.example.com/a")},
+		{2, mustParseURL("http://c.example.com/")},
+		{3, nil},
+		{4, mustParseURL("https://a.example.com/z")},
+		{5, mustParseURL("http://b.example.com/b")},
+	}
+	AscByField(ls, "URL")
+	c := []int{3, 5, 2, 4, 1}
+	for i, v := range ls {
+		if v.Id != c[i] {
+			t.Errorf("ls[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	s := New(ls, FieldGetter("URL"), Ascending)
+	s.URLByHost = true
+	s.Policy.Nils = Last
+	s.Sort()
+	c = []int{4, 1, 5, 2, 3}
+	for i, v := range ls {
+		if v.Id != c[i] {
+			t.Errorf("ls[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+type Event struct {
+	Name string
+	When time.Time
+}
+
+func TestTimeResolution(t *testing.T) {
+	base := time.Date(2012, 5, 26, 10, 0, 0, 0, time.UTC)
+	es := []Event{
+		{"a", base.Add(500 * time.Millisecond)},
+		{"b", base.Add(100 * time.Millisecond)},
+		{"c", base.Add(-100 * time.Millisecond)},
+	}
+	Sort(es, FieldGetter("When"), Ascending)
+	if es[0].Name != "c" || es[1].Name != "b" || es[2].Name != "a" {
+		t.Errorf("Events weren't sorted by their exact times: %v", es)
+	}
+	s := New(es, FieldGetter("When"), DescendingStableTies)
+	s.TimeResolution = time.Second
+	s.Sort()
+	// b and a are within the same second, and keep their order
+	if es[0].Name != "b" || es[1].Name != "a" || es[2].Name != "c" {
+		t.Errorf("Events weren't sorted by their times truncated to seconds: %v", es)
+	}
+}
+
+func TestAscByTimeOfDay(t *testing.T) {
+	es := []Event{
+		{"lunch", time.Date(2012, 5, 1, 12, 30, 0, 0, time.UTC)},
+		{"breakfast", time.Date(2012, 5, 3, 8, 0, 0, 0, time.UTC)},
+		{"dinner", time.Date(2011, 1, 1, 19, 15, 0, 0, time.UTC)},
+		{"snack", time.Date(2012, 5, 2, 12, 29, 59, 0, time.UTC)},
+	}
+	Sort(es, TimeComponentGetter("When", TimeComponentTimeOfDay), Ascending)
+	c := []string{"breakfast", "snack", "lunch", "dinner"}
+	for i, v := range es {
+		if v.Name != c[i] {
+			t.Errorf("es[%d].Name is not %s, but %s", i, c[i], v.Name)
+		}
+	}
+}
+
+func TestDescByMonth(t *testing.T) {
+	ts := []time.Time{
+		time.Date(2012, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2010, 11, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	Sort(ts, TimeComponentGetter("", TimeComponentMonth), Descending)
+	c := []time.Month{time.November, time.March, time.January}
+	for i, v := range ts {
+		if v.Month() != c[i] {
+			t.Errorf("ts[%d].Month() is not %v, but %v", i, c[i], v.Month())
+		}
+	}
+}
+
+type Record struct {
+	Id    int
+	Score *int
+	Name  string
+	Ratio float64
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func records() []Record {
+	nan := math.NaN()
+	return []Record{
+		{1, intPtr(3), "c", 0.5},
+		{2, nil, "", nan},
+		{3, intPtr(1), "a", 0.25},
+		{4, nil, "b", 1},
+		{5, intPtr(2), "", nan},
+	}
+}
+
+func recordIds(rs []Record) []int {
+	ids := make([]int, len(rs))
+	for i, v := range rs {
+		ids[i] = v.Id
+	}
+	return ids
+}
+
+func testPolicy(t *testing.T, name string, ordering Ordering, p Policy, c []int) {
+	rs := records()
+	s := New(rs, FieldGetter(name), ordering)
+	s.Policy = p
+	s.Sort()
+	ids := recordIds(rs)
+	for i := range c {
+		if ids[i] != c[i] {
+			t.Errorf("Sorting by %s in %v order with %+v: ids are not %v, but %v", name, ordering, p, c, ids)
+			return
+		}
+	}
+}
+
+func TestPolicyNils(t *testing.T) {
+	testPolicy(t, "Score", Ascending, Policy{}, []int{2, 4, 3, 5, 1})
+	testPolicy(t, "Score", Descending, Policy{}, []int{1, 5, 3, 2, 4})
+	testPolicy(t, "Score", Ascending, Policy{Nils: Last}, []int{3, 5, 1, 2, 4})
+	testPolicy(t, "Score", Descending, Policy{Nils: First}, []int{2, 4, 1, 5, 3})
+}
+
+func TestPolicyEmpties(t *testing.T) {
+	testPolicy(t, "Name", Ascending, Policy{}, []int{2, 5, 3, 4, 1})
+	testPolicy(t, "Name", Ascending, Policy{Empties: Last}, []int{3, 4, 1, 2, 5})
+	testPolicy(t, "Name", Descending, Policy{Empties: First}, []int{2, 5, 1, 4, 3})
+	testPolicy(t, "Name", CaseInsensitiveDescending, Policy{Empties: Last}, []int{1, 4, 3, 2, 5})
+}
+
+func TestPolicyNaNs(t *testing.T) {
+	testPolicy(t, "Ratio", Ascending, Policy{NaNs: Last}, []int{3, 1, 4, 2, 5})
+	testPolicy(t, "Ratio", Descending, Policy{NaNs: First}, []int{2, 5, 4, 1, 3})
+}
+
+func TestPolicyCombined(t *testing.T) {
+	// Only the placement for the kind of value being sorted by applies
+	p := Policy{Nils: Last, Empties: First, NaNs: First}
+	testPolicy(t, "Score", Ascending, p, []int{3, 5, 1, 2, 4})
+	testPolicy(t, "Name", Ascending, p, []int{2, 5, 3, 4, 1})
+	testPolicy(t, "Ratio", Descending, p, []int{2, 5, 4, 1, 3})
+}
+
+func TestPolicyAsError(t *testing.T) {
+	for _, name := range []string{"Score", "Name", "Ratio"} {
+		func() {
+			defer func() {
+				if x := recover(); x == nil {
+					t.Errorf("Sorting by %s with AsError placements didn't cause a panic", name)
+				}
+			}()
+			rs := records()
+			s := New(rs, FieldGetter(name), Ascending)
+			s.Policy = Policy{Nils: AsError, Empties: AsError, NaNs: AsError}
+			s.Sort()
+		}()
+	}
+}
+
+type TestStruct struct {
+	TimePtr    *time.Time
+	Invalid    InvalidType
+	unexported int
+}
+
+type InvalidType struct {
+	Foo string
+	Bar int
+}
+
+func testStructs() []TestStruct {
+	return []TestStruct{
+		{
+			TimePtr:    &now,
+			Invalid:    InvalidType{"foo", 123},
+			unexported: 5,
+		},
+		{
+			TimePtr:    &now,
+			Invalid:    InvalidType{"bar", 456},
+			unexported: 6,
+		},
+	}
+}
+
+func testPointers() []*TestStruct {
+	return []*TestStruct{
+		{
+			TimePtr:    &now,
+			Invalid:    InvalidType{"foo", 123},
+			unexported: 5,
+		},
+		{
+			TimePtr:    &now,
+			Invalid:    InvalidType{"bar", 456},
+			unexported: 6,
+		},
+	}
+}
+
+func TestAscByFieldInvalidType(t *testing.T) {
+	// Sorting by an invalid type should cause a panic
+	defer func() {
+		if x := recover(); x == nil {
+			t.Fatal("Sorting by an unrecognized type didn't cause a panic")
+		}
+	}()
+	is := testStructs()
+	AscByField(is, "Invalid")
+}
+
+func TestAscByFieldUnexportedType(t *testing.T) {
+	// Sorting an unexported type should cause a panic
+	// TODO: This should test on a field outside the package
+	return // TEMP
+	defer func() {
+		if x := recover(); x == nil {
+			t.Fatal("Sorting an unexported type didn't cause a panic")
+		}
+	}()
+	is := testStructs()
+	AscByField(is, "unexported")
+}
+
+func TestAscByFieldPointer(t *testing.T) {
+	// Sorting by a pointer type shouldn't cause a panic
+	is := testStructs()
+	AscByField(is, "TimePtr")
+}
+
+type Optional struct {
+	Id    int
+	Count *int
+	Label *string
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func optionals() []Optional {
+	return []Optional{
+		{1, intPtr(30), stringPtr("b")},
+		{2, nil, stringPtr("c")},
+		{3, intPtr(10), nil},
+		{4, intPtr(20), stringPtr("a")},
+	}
+}
+
+func TestAscByFieldIntPointer(t *testing.T) {
+	opts := optionals()
+	AscByField(opts, "Count")
+	c := []int{2, 3, 4, 1}
+	for i, v := range opts {
+		if v.Id != c[i] {
+			t.Errorf("opts[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestDescByFieldStringPointer(t *testing.T) {
+	opts := optionals()
+	DescByField(opts, "Label")
+	c := []int{2, 1, 4, 3}
+	for i, v := range opts {
+		if v.Id != c[i] {
+			t.Errorf("opts[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	opts = optionals()
+	s := New(opts, FieldGetter("Label"), Ascending)
+	s.Policy.Nils = Last
+	s.Sort()
+	c = []int{4, 1, 2, 3}
+	for i, v := range opts {
+		if v.Id != c[i] {
+			t.Errorf("opts[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestPointerSliceAscByFieldInt64(t *testing.T) {
+	// Sorting a slice of pointers shouldn't cause a panic
+	is := pointers()
+	AscByField(is, "Id")
+}
+
+func TestPointerSliceAscByFieldPointer(t *testing.T) {
+	// Sorting a slice of pointers by a pointer type shouldn't cause a panic
+	is := testPointers()
+	AscByField(is, "TimePtr")
+}
+
+func TestPointerSliceAscByFieldInt64Order(t *testing.T) {
+	is := pointers()
+	AscByField(is, "Id")
+	for i, v := range is {
+		if v.Id != int64(i+1) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+		}
+	}
+}
+
+func TestSortSkipErrors(t *testing.T) {
+	// The Point has no Id field, and should be moved to the end
+	var xs []interface{}
+	for _, v := range items() {
+		xs = append(xs, v)
+	}
+	xs = append(xs[:3], append([]interface{}{Point{1, 2}}, xs[3:]...)...)
+	skipped := SortSkipErrors(xs, FieldGetter("Id"), Ascending)
+	if skipped != 1 {
+		t.Errorf("Skipped %d elements, not 1", skipped)
+	}
+	l := len(xs)
+	if p, ok := xs[l-1].(Point); !ok || p != (Point{1, 2}) {
+		t.Errorf("Last element is not the Point, but %v", xs[l-1])
+	}
+	for i, v := range xs[:l-1] {
+		if id := v.(Item).Id; id != int64(i+1) {
+			t.Errorf("xs[%d].Id is not %d, but %d", i, i+1, id)
+		}
+	}
+}
+
+func TestSortSkipErrorsNil(t *testing.T) {
+	// The nil element has no Id field either
+	is := pointers()
+	is = append(is[:3], append([]*Item{nil}, is[3:]...)...)
+	if skipped := SortSkipErrors(is, FieldGetter("Id"), Ascending); skipped != 1 {
+		t.Errorf("Skipped %d elements, not 1", skipped)
+	}
+	if is[len(is)-1] != nil {
+		t.Errorf("Last element is not nil, but %v", is[len(is)-1])
+	}
+}
+
+func TestSortSkipErrorsNilField(t *testing.T) {
+	// Nil pointer fields aren't skipped, but placed according to Policy
+	rs := []interface{}{
+		Record{Id: 1, Score: intPtr(2)},
+		Point{1, 2},
+		Record{Id: 2},
+		Record{Id: 3, Score: intPtr(1)},
+	}
+	s := New(rs, FieldGetter("Score"), Ascending)
+	s.SkipErrors = true
+	s.Policy = Policy{Nils: First}
+	s.Sort()
+	if s.Skipped() != 1 {
+		t.Errorf("Skipped %d elements, not 1", s.Skipped())
+	}
+	var ids []int
+	for _, v := range rs[:3] {
+		ids = append(ids, v.(Record).Id)
+	}
+	if c := []int{2, 3, 1}; !reflect.DeepEqual(ids, c) {
+		t.Errorf("Records sorted by Score with nils first are %v, not %v", ids, c)
+	}
+	if _, ok := rs[3].(Point); !ok {
+		t.Errorf("Last element is not the Point, but %v", rs[3])
+	}
+}
+
+func TestSortWithoutSkipErrors(t *testing.T) {
+	defer func() {
+		if x := recover(); x == nil {
+			t.Fatal("Sorting by a missing field didn't cause a panic")
+		}
+	}()
+	is := append(pointers(), nil)
+	AscByField(is, "Id")
+}
+
+func TestAscSlicePointer(t *testing.T) {
+	ints := []int{4, 3, 1, 5, 2}
+	Asc(&ints)
+	if !sort.IntsAreSorted(ints) {
+		t.Errorf("Ints weren't sorted through a pointer: %v", ints)
+	}
+}
+
+func TestAscArrayPointer(t *testing.T) {
+	ints := [...]int{4, 3, 1, 5, 2}
+	Asc(&ints)
+	if !sort.IntsAreSorted(ints[:]) {
+		t.Errorf("Array ints weren't sorted through a pointer: %v", ints)
+	}
+}
+
+func TestAscByFieldSlicePointer(t *testing.T) {
+	is := items()
+	AscByField(&is, "Id")
+	for i, v := range is {
+		if v.Id != int64(i+1) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+		}
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	a := []int{1, 4, 9}
+	b := []int{2, 3, 10, 11}
+	c := []int{0, 4, 5}
+	merged := MergeSorted(Ascending, nil, a, b, c).([]int)
+	correct := []int{0, 1, 2, 3, 4, 4, 5, 9, 10, 11}
+	if !reflect.DeepEqual(merged, correct) {
+		t.Errorf("Merged slice was not %v: %v", correct, merged)
+	}
+}
+
+func TestMergeSortedByField(t *testing.T) {
+	is := items()
+	a, b, c := is[:3], is[3:5], is[5:]
+	for _, v := range [][]Item{a, b, c} {
+		DescByField(v, "Id")
+	}
+	merged := MergeSorted(Descending, FieldGetter("Id"), a, b, c).([]Item)
+	l := len(merged)
+	if l != len(is) {
+		t.Fatalf("Merged slice has %d elements, not %d", l, len(is))
+	}
+	for i, v := range merged {
+		if v.Id != int64(l-i) {
+			t.Errorf("merged[%d].Id is not %d, but %d", i, l-i, v.Id)
+		}
+	}
+}
+
+type Tagged struct {
+	Name  string
+	Value interface{}
+}
+
+func TestAscByFieldInterface(t *testing.T) {
+	ts := []Tagged{{"c", 3}, {"a", 1}, {"d", 4}, {"b", 2}}
+	AscByField(ts, "Value")
+	for i, v := range ts {
+		if v.Value.(int) != i+1 {
+			t.Errorf("ts[%d].Value is not %d, but %v", i, i+1, v.Value)
+		}
+	}
+}
+
+func TestDescInterfaceSlice(t *testing.T) {
+	xs := []interface{}{3, 1, 4, 2}
+	Desc(xs)
+	correct := []interface{}{4, 3, 2, 1}
+	if !reflect.DeepEqual(xs, correct) {
+		t.Errorf("Sorted slice was not %v: %v", correct, xs)
+	}
+}
+
+func TestAscByFieldInterfaceSlice(t *testing.T) {
+	xs := []interface{}{Item{Id: 2}, &Item{Id: 3}, Item{Id: 1}}
+	AscByField(xs, "Id")
+	for i, v := range xs {
+		if id := reflect.Indirect(reflect.ValueOf(v)).FieldByName("Id").Int(); id != int64(i+1) {
+			t.Errorf("xs[%d].Id is not %d, but %d", i, i+1, id)
+		}
+	}
+}
+
+func TestSortRange(t *testing.T) {
+	ints := []int{9, 8, 7, 6, 5, 4, 3, 2, 1}
+	SortRange(ints, nil, Ascending, 2, 6)
+	correct := []int{9, 8, 4, 5, 6, 7, 3, 2, 1}
+	if !reflect.DeepEqual(ints, correct) {
+		t.Errorf("Range-sorted slice was not %v: %v", correct, ints)
+	}
+	is := items()
+	SortRange(is, FieldGetter("Id"), Descending, 0, 3)
+	c := []int64{9, 6, 1, 3, 7, 2, 8, 5, 4}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestSortRangeInvalidBounds(t *testing.T) {
+	for _, r := range [][2]int{{-1, 2}, {3, 2}, {0, 10}} {
+		func() {
+			defer func() {
+				if x := recover(); x == nil {
+					t.Errorf("Sorting the range %v didn't cause a panic", r)
+				}
+			}()
+			SortRange([]int{3, 2, 1}, nil, Ascending, r[0], r[1])
+		}()
+	}
+}
+
+func TestBoundedSorter(t *testing.T) {
+	b := NewBounded(10, nil, Descending)
+	for i := 0; i < 1000; i++ {
+		b.Push((i * 7919) % 1000)
+	}
+	if b.Len() != 10 {
+		t.Errorf("BoundedSorter kept %d elements, not 10", b.Len())
+	}
+	top := b.Slice().([]int)
+	correct := []int{999, 998, 997, 996, 995, 994, 993, 992, 991, 990}
+	if !reflect.DeepEqual(top, correct) {
+		t.Errorf("Kept elements were not %v: %v", correct, top)
+	}
+}
+
+func TestBoundedSorterPushNil(t *testing.T) {
+	b := NewBounded(3, nil, Ascending)
+	b.Push(1)
+	defer func() {
+		x := recover()
+		if msg, ok := x.(string); !ok || !strings.Contains(msg, "nil") {
+			t.Errorf("Pushing nil panicked with %v", x)
+		}
+		if b.Len() != 1 {
+			t.Errorf("BoundedSorter kept %d elements after pushing nil, not 1", b.Len())
+		}
+	}()
+	b.Push(nil)
+}
+
+func TestBoundedSorterByField(t *testing.T) {
+	b := NewBounded(3, FieldGetter("Id"), Ascending)
+	if b.Slice() != nil {
+		t.Error("Slice of an empty BoundedSorter is not nil")
+	}
+	for _, v := range pointers() {
+		b.Push(v)
+	}
+	is := b.Slice().([]*Item)
+	for i, v := range is {
+		if v.Id != int64(i+1) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+		}
+	}
+}
+
+type Measurement struct {
+	Id    int
+	Value float64
+}
+
+func TestSortFloats(t *testing.T) {
+	var buf []float64
+	for n := 0; n < 100; n += 7 {
+		ms := make([]Measurement, n)
+		for i := range ms {
+			ms[i] = Measurement{i, float64((i * 37) % 11)}
+		}
+		ms = append(ms, Measurement{-1, math.NaN()})
+		buf = SortFloats(buf, ms, FieldGetter("Value"), Ascending)
+		if !math.IsNaN(ms[0].Value) {
+			t.Errorf("NaN is not first, but %v", ms[0].Value)
+		}
+		for i := 2; i < len(ms); i++ {
+			if ms[i-1].Value > ms[i].Value {
+				t.Errorf("Measurements weren't sorted: %v", ms)
+				break
+			}
+		}
+		buf = SortFloats(buf, ms, FieldGetter("Value"), Descending)
+		for i := 1; i < len(ms)-1; i++ {
+			if ms[i-1].Value < ms[i].Value {
+				t.Errorf("Measurements weren't sorted in descending order: %v", ms)
+				break
+			}
+		}
+	}
+	fs := []float64{3, 1, 2}
+	SortFloats(nil, fs, nil, Ascending)
+	if !reflect.DeepEqual(fs, []float64{1, 2, 3}) {
+		t.Errorf("Floats weren't sorted: %v", fs)
+	}
+	SortFloats(nil, &fs, nil, Descending)
+	if !reflect.DeepEqual(fs, []float64{3, 2, 1}) {
+		t.Errorf("Floats weren't sorted through a pointer: %v", fs)
+	}
+	f32s := []float32{2, 3, 1}
+	SortFloats(nil, &f32s, nil, Ascending)
+	if !reflect.DeepEqual(f32s, []float32{1, 2, 3}) {
+		t.Errorf("Float32s weren't sorted through a pointer: %v", f32s)
+	}
+	ms := []Measurement{{1, 2}, {2, 1}}
+	SortFloats(nil, &ms, FieldGetter("Value"), Ascending)
+	if ms[0].Id != 2 {
+		t.Errorf("Measurements weren't sorted through a pointer: %v", ms)
+	}
+	buf = append(buf[:0], 1)
+	if buf = SortFloats(buf, (*[]float64)(nil), nil, Ascending); len(buf) != 0 {
+		t.Errorf("Sorting a nil *[]float64 returned %v", buf)
+	}
+	buf = append(buf[:0], 1)
+	if buf = SortFloats(buf, (*[]float32)(nil), nil, Ascending); len(buf) != 0 {
+		t.Errorf("Sorting a nil *[]float32 returned %v", buf)
+	}
+	buf = append(buf[:0], 1)
+	if buf = SortFloats(buf, (*[]Measurement)(nil), FieldGetter("Value"), Ascending); len(buf) != 0 {
+		t.Errorf("Sorting a nil *[]Measurement returned %v", buf)
+	}
+	buf = buf[:0]
+	if n := testing.AllocsPerRun(10, func() {
+		buf = SortFloats(buf, &fs, nil, Descending)
+	}); n != 0 {
+		t.Errorf("Sorting floats with a reused buffer took %v allocations", n)
+	}
+}
+
+func TestAscReverseSorted(t *testing.T) {
+	is := items()
+	DescByField(is, "Id")
+	AscByField(is, "Id")
+	for i, v := range is {
+		if v.Id != int64(i+1) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+		}
+	}
+	// Equal elements mean the slice isn't strictly reverse-sorted
+	ints := []int{5, 4, 4, 3, 1}
+	Asc(ints)
+	if !reflect.DeepEqual(ints, []int{1, 3, 4, 4, 5}) {
+		t.Errorf("Ints weren't sorted: %v", ints)
+	}
+}
+
+func TestArgSort(t *testing.T) {
+	is := items()
+	order := ArgSort(is, FieldGetter("Id"), Ascending)
+	c := []int{1, 5, 3, 8, 7, 0, 4, 6, 2}
+	if !reflect.DeepEqual(order, c) {
+		t.Errorf("Order is not %v, but %v", c, order)
+	}
+	if !reflect.DeepEqual(is, items()) {
+		t.Error("ArgSort modified the slice")
+	}
+}
+
+func TestSortWithPermutation(t *testing.T) {
+	is := items()
+	perm, inverse := SortWithPermutation(is, FieldGetter("Id"), Ascending)
+	orig := items()
+	for i, v := range is {
+		if v.Id != int64(i+1) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+		}
+		if orig[perm[i]].Id != v.Id {
+			t.Errorf("perm[%d] is %d, but orig[%d].Id is not %d", i, perm[i], perm[i], v.Id)
+		}
+	}
+	restored := make([]Item, len(is))
+	for i := range restored {
+		restored[i] = is[inverse[i]]
+	}
+	if !reflect.DeepEqual(restored, orig) {
+		t.Errorf("Applying the inverse permutation didn't restore the original order: %v", restored)
+	}
+}
+
+func TestMinMaxByField(t *testing.T) {
+	is := items()
+	if min := MinByField(is, "Id").(Item); min.Id != 1 {
+		t.Errorf("Min Id is not 1, but %d", min.Id)
+	}
+	if max := MaxByField(is, "Date").(Item); max.Id != 7 {
+		t.Errorf("Item with the max Date does not have Id 7, but %d", max.Id)
+	}
+	opts := optionals()
+	if max := MaxByField(opts, "Count").(Optional); max.Id != 1 {
+		t.Errorf("Item with the max Count does not have Id 1, but %d", max.Id)
+	}
+	if min := MinByField(opts, "Label").(Optional); min.Id != 4 {
+		t.Errorf("Item with the min Label does not have Id 4, but %d", min.Id)
+	}
+	if min := MinByField([]Item{}, "Id"); min != nil {
+		t.Errorf("Min of an empty slice is not nil, but %v", min)
+	}
+	min, max := MinMaxByField(is, "Id")
+	if min.(Item).Id != 1 || max.(Item).Id != 9 {
+		t.Errorf("Items with min and max Id are %v and %v", min, max)
+	}
+	min, max = MinMaxByField(is, "Name")
+	if min.(Item).Name != MinByField(is, "Name").(Item).Name || max.(Item).Name != MaxByField(is, "Name").(Item).Name {
+		t.Errorf("Items with min and max Name are %v and %v", min, max)
+	}
+	min, max = MinMaxByField(opts, "Label")
+	if min.(Optional).Id != 4 || max.(Optional).Id != MaxByField(opts, "Label").(Optional).Id {
+		t.Errorf("Items with min and max Label are %v and %v", min, max)
+	}
+	if min, max := MinMaxByField([]Item{}, "Id"); min != nil || max != nil {
+		t.Errorf("MinMaxByField of an empty slice returned %v and %v", min, max)
+	}
+}
+
+func TestTopN(t *testing.T) {
+	is := items()
+	top := TopN(is, FieldGetter("Date"), Descending, 2).([]Item)
+	if len(top) != 2 || top[0].Id != 7 || top[1].Id != 5 {
+		t.Errorf("Top items by descending Date are not 7 and 5: %v", top)
+	}
+	if !reflect.DeepEqual(is, items()) {
+		t.Error("TopN modified the slice")
+	}
+	ints := TopN([]int{3, 1, 2}, nil, Ascending, 5).([]int)
+	if !reflect.DeepEqual(ints, []int{1, 2, 3}) {
+		t.Errorf("TopN of fewer than n ints is not all of them: %v", ints)
+	}
+}
+
+func TestTopNWhere(t *testing.T) {
+	valid := func(v reflect.Value) bool {
+		return v.FieldByName("Valid").Bool()
+	}
+	top := TopNWhere(items(), FieldGetter("Id"), Ascending, 3, valid).([]Item)
+	c := []int64{1, 4, 6}
+	if len(top) != len(c) {
+		t.Fatalf("TopNWhere returned %d items, not %d", len(top), len(c))
+	}
+	for i, v := range top {
+		if v.Id != c[i] {
+			t.Errorf("top[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	ps := append(pointers(), nil)
+	nonNil := func(v reflect.Value) bool {
+		return !v.IsNil()
+	}
+	tops := TopNWhere(ps, FieldGetter("Id"), Descending, 2, nonNil).([]*Item)
+	if len(tops) != 2 || tops[0].Id != 9 || tops[1].Id != 8 {
+		t.Errorf("TopNWhere excluding a nil element returned %v", tops)
+	}
+	none := TopNWhere(items(), FieldGetter("Id"), Ascending, 3, func(reflect.Value) bool { return false }).([]Item)
+	if len(none) != 0 {
+		t.Errorf("TopNWhere with no matches returned %v", none)
+	}
+}
+
+func TestSortStrings(t *testing.T) {
+	cases := []struct {
+		ordering Ordering
+		want     []string
+	}{
+		{Ascending, []string{"B", "a", "c"}},
+		{Descending, []string{"c", "a", "B"}},
+		{CaseInsensitiveAscending, []string{"a", "B", "c"}},
+		{CaseInsensitiveDescending, []string{"c", "B", "a"}},
+	}
+	for _, c := range cases {
+		s := []string{"c", "a", "B"}
+		SortStrings(s, c.ordering)
+		if !reflect.DeepEqual(s, c.want) {
+			t.Errorf("SortStrings with %v gave %v, not %v", c.ordering, s, c.want)
+		}
+	}
+	for _, o := range []Ordering{NonZeroFirstAscending, NonZeroFirstDescending, ModularAscending, ModularDescending} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SortStrings didn't panic on %v", o)
+				}
+			}()
+			SortStrings([]string{"b", "a"}, o)
+		}()
+	}
+}
+
+func TestSortInts(t *testing.T) {
+	s := []int{3, -1, 2, 0}
+	SortInts(s, Ascending)
+	if !reflect.DeepEqual(s, []int{-1, 0, 2, 3}) {
+		t.Errorf("Ascending ints not sorted: %v", s)
+	}
+	SortInts(s, Descending)
+	if !reflect.DeepEqual(s, []int{3, 2, 0, -1}) {
+		t.Errorf("Descending ints not sorted: %v", s)
+	}
+	for _, o := range []Ordering{CaseInsensitiveAscending, CaseInsensitiveDescending, NonZeroFirstAscending, NonZeroFirstDescending, ModularAscending, ModularDescending} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SortInts didn't panic on %v", o)
+				}
+			}()
+			SortInts(s, o)
+		}()
+	}
+}
+
+func TestNonZeroFirstInts(t *testing.T) {
+	s := []int{0, 3, -1, 0, 2, 0}
+	Sort(s, nil, NonZeroFirstAscending)
+	if c := []int{-1, 2, 3, 0, 0, 0}; !reflect.DeepEqual(s, c) {
+		t.Errorf("NonZeroFirstAscending ints are %v, not %v", s, c)
+	}
+	Sort(s, nil, NonZeroFirstDescending)
+	if c := []int{3, 2, -1, 0, 0, 0}; !reflect.DeepEqual(s, c) {
+		t.Errorf("NonZeroFirstDescending ints are %v, not %v", s, c)
+	}
+}
+
+func TestNonZeroFirstByFieldString(t *testing.T) {
+	is := []Item{{Id: 1}, {Id: 2, Name: "b"}, {Id: 3}, {Id: 4, Name: "a"}, {Id: 5, Name: "c"}}
+	Sort(is, FieldGetter("Name"), NonZeroFirstAscending)
+	c := []string{"a", "b", "c", "", ""}
+	for i, v := range is {
+		if v.Name != c[i] {
+			t.Errorf("is[%d].Name is not %q, but %q", i, c[i], v.Name)
+		}
+	}
+	Sort(is, FieldGetter("Name"), NonZeroFirstDescending)
+	c = []string{"c", "b", "a", "", ""}
+	for i, v := range is {
+		if v.Name != c[i] {
+			t.Errorf("is[%d].Name is not %q, but %q", i, c[i], v.Name)
+		}
+	}
+}
+
+func TestSortWithMoves(t *testing.T) {
+	orig := items()
+	is := items()
+	moved := make([]Item, len(is))
+	copy(moved, orig)
+	n := 0
+	SortWithMoves(is, FieldGetter("Id"), Ascending, func(from, to int) {
+		if from == to {
+			t.Errorf("Element at %d reported as moved to itself", from)
+		}
+		moved[to] = orig[from]
+		n++
+	})
+	if !reflect.DeepEqual(moved, is) {
+		t.Errorf("Moves don't reconstruct the sorted order: %v != %v", moved, is)
+	}
+	if n != len(is) {
+		t.Errorf("%d moves were reported, not %d", n, len(is))
+	}
+}
+
+type Schedule struct {
+	Month   time.Month
+	Weekday time.Weekday
+}
+
+func TestAscByFieldTimeMonthWeekday(t *testing.T) {
+	ss := []Schedule{
+		{time.December, time.Saturday},
+		{time.January, time.Wednesday},
+		{time.July, time.Sunday},
+		{time.March, time.Monday},
+	}
+	AscByField(ss, "Month")
+	months := []time.Month{time.January, time.March, time.July, time.December}
+	for i, v := range ss {
+		if v.Month != months[i] {
+			t.Errorf("ss[%d].Month is not %v, but %v", i, months[i], v.Month)
+		}
+	}
+	AscByField(ss, "Weekday")
+	days := []time.Weekday{time.Sunday, time.Monday, time.Wednesday, time.Saturday}
+	for i, v := range ss {
+		if v.Weekday != days[i] {
+			t.Errorf("ss[%d].Weekday is not %v, but %v", i, days[i], v.Weekday)
+		}
+	}
+	ms := []time.Month{time.May, time.February, time.November}
+	Desc(ms)
+	if c := []time.Month{time.November, time.May, time.February}; !reflect.DeepEqual(ms, c) {
+		t.Errorf("Descending months are %v, not %v", ms, c)
+	}
+}
+
+func TestCiAscByFieldTimeMonth(t *testing.T) {
+	defer func() {
+		x := recover()
+		if x == nil {
+			t.Fatal("Sorting months case-insensitively didn't cause a panic")
+		}
+		if msg, _ := x.(string); !strings.Contains(msg, "time.Month") || !strings.Contains(msg, "case-insensitive") {
+			t.Errorf("Unclear panic message: %v", x)
+		}
+	}()
+	ss := []Schedule{{Month: time.May}, {Month: time.April}}
+	CiAscByField(ss, "Month")
+}
+
+func TestReverseTyped(t *testing.T) {
+	ints := []int{1, 2, 3, 4}
+	ReverseInts(ints)
+	if c := []int{4, 3, 2, 1}; !reflect.DeepEqual(ints, c) {
+		t.Errorf("Reversed even-length ints are %v, not %v", ints, c)
+	}
+	strs := []string{"a", "b", "c"}
+	ReverseStrings(strs)
+	if c := []string{"c", "b", "a"}; !reflect.DeepEqual(strs, c) {
+		t.Errorf("Reversed odd-length strings are %v, not %v", strs, c)
+	}
+	fs := []float64{1.5, 2.5, 3.5, 4.5, 5.5}
+	Reverse(fs)
+	if c := []float64{5.5, 4.5, 3.5, 2.5, 1.5}; !reflect.DeepEqual(fs, c) {
+		t.Errorf("Reversed odd-length floats are %v, not %v", fs, c)
+	}
+	empty := []int{}
+	ReverseInts(empty)
+	one := []string{"a"}
+	Reverse(one)
+	if one[0] != "a" {
+		t.Errorf("Reversed single string is %v", one)
+	}
+}
+
+func TestParseOrdering(t *testing.T) {
+	cases := map[string]Ordering{
+		"":                          Ascending,
+		"asc":                       Ascending,
+		"DESC":                      Descending,
+		"ci-asc":                    CaseInsensitiveAscending,
+		"ci-desc":                   CaseInsensitiveDescending,
+		"descending":                Descending,
+		"DescendingStableTies":      DescendingStableTies,
+		"caseinsensitivedescending": CaseInsensitiveDescending,
+	}
+	for in, want := range cases {
+		o, err := ParseOrdering(in)
+		if err != nil {
+			t.Errorf("ParseOrdering(%q) returned error: %v", in, err)
+		} else if o != want {
+			t.Errorf("ParseOrdering(%q) is %v, not %v", in, o, want)
+		}
+	}
+	if _, err := ParseOrdering("sideways"); err == nil {
+		t.Error("ParseOrdering of an unknown ordering didn't return an error")
+	}
+}
+
+func TestSortBySpecs(t *testing.T) {
+	is := []Item{
+		{Id: 1, Name: "b"},
+		{Id: 2, Name: "A"},
+		{Id: 3, Name: "B"},
+		{Id: 4, Name: "a"},
+	}
+	err := SortBySpecs(is, []SortSpec{{"Name", "ci-asc"}, {"Id", "desc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := []int64{4, 2, 3, 1}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	if err := SortBySpecs(is, []SortSpec{{"Name", "up"}}); err == nil {
+		t.Error("SortBySpecs with an invalid direction didn't return an error")
+	}
+	if err := SortBySpecs(is, []SortSpec{{"Missing", "asc"}}); err == nil {
+		t.Error("SortBySpecs with an unknown field didn't return an error")
+	}
+	if err := SortBySpecs(is, []SortSpec{{"Name", "asc"}, {"Id", "ci-desc"}}); err == nil {
+		t.Error("SortBySpecs with a case-insensitive direction for an int field didn't return an error")
+	}
+	if err := SortBySpecs(5, []SortSpec{{"Name", "asc"}}); err == nil {
+		t.Error("SortBySpecs with an int didn't return an error")
+	}
+	if err := SortBySpecs(nil, []SortSpec{{"Name", "asc"}}); err != nil {
+		t.Errorf("SortBySpecs with nil returned %v", err)
+	}
+	if err := SortBySpecs(nil, []SortSpec{{"Name", "up"}}); err == nil {
+		t.Error("SortBySpecs with nil and an invalid direction didn't return an error")
+	}
+	if err := SortBySpecs((*[]Item)(nil), []SortSpec{{"Missing", "asc"}}); err == nil {
+		t.Error("SortBySpecs with a nil *[]Item and an unknown field didn't return an error")
+	}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("Failed SortBySpecs modified the slice: is[%d].Id is %d", i, v.Id)
+		}
+	}
+}
+
+// Composes the decomposed forms of a few accented letters, standing in for
+// norm.NFC.String.
+var composeAccents = strings.NewReplacer(
+	"e\u0301", "\u00e9",
+	"E\u0301", "\u00c9",
+	"a\u0300", "\u00e0",
+).Replace
+
+func TestSortNormalized(t *testing.T) {
+	nfc := "Ren\u00e9e"
+	nfd := "Rene\u0301e"
+	if nfc == nfd {
+		t.Fatal("NFC and NFD forms are equal")
+	}
+	is := []Item{{Id: 1, Name: nfd}, {Id: 2, Name: "Renf"}, {Id: 3, Name: nfc}, {Id: 4, Name: "Rena"}}
+	s := New(is, FieldGetter("Name"), Ascending)
+	s.Normalize = composeAccents
+	s.Sort()
+	// Without normalization, the NFD form sorts before "Renf"
+	if is[0].Id != 4 || is[1].Id != 2 || is[2].Id+is[3].Id != 4 {
+		t.Errorf("Normalized names are not sorted: %v", is)
+	}
+	if is[2].Name != nfd && is[3].Name != nfd {
+		t.Error("Normalize modified the sorted values")
+	}
+	cmp := New([]string{nfc, nfd}, nil, Ascending)
+	cmp.Normalize = composeAccents
+	cmp.setup()
+	l := cmp.lesser()
+	if l.Less(0, 1) || l.Less(1, 0) {
+		t.Error("NFC and NFD forms don't compare equal when normalized")
+	}
+}
+
+func TestAscBySum(t *testing.T) {
+	rows := [][]int{{4, 5, 1}, {2, 1, 7, -5}, {9, 3, 3}, {}, {1, 6, 2}}
+	AscBySum(rows)
+	c := [][]int{{}, {2, 1, 7, -5}, {1, 6, 2}, {4, 5, 1}, {9, 3, 3}}
+	if !reflect.DeepEqual(rows, c) {
+		t.Errorf("Rows sorted by ascending sum are %v, not %v", rows, c)
+	}
+	fs := [][2]float64{{0.5, 0.25}, {0.1, 0.1}, {1, -0.5}}
+	DescBySum(fs)
+	fc := [][2]float64{{0.5, 0.25}, {1, -0.5}, {0.1, 0.1}}
+	if !reflect.DeepEqual(fs, fc) {
+		t.Errorf("Rows sorted by descending sum are %v, not %v", fs, fc)
+	}
+	us := [][]uint8{{200, 200}, {255}}
+	AscBySum(us)
+	if us[0][0] != 255 {
+		t.Errorf("uint8 sums overflowed: %v", us)
+	}
+}
+
+func TestSortedInsert(t *testing.T) {
+	var is []Item
+	for _, v := range items() {
+		SortedInsert(&is, v, FieldGetter("Id"), Ascending)
+		if !sort.SliceIsSorted(is, func(i, j int) bool { return is[i].Id < is[j].Id }) {
+			t.Fatalf("Slice isn't sorted after inserting %d: %v", v.Id, is)
+		}
+	}
+	if len(is) != len(items()) {
+		t.Fatalf("Slice has %d items, not %d", len(is), len(items()))
+	}
+	if i := SortedInsert(&is, Item{Id: 5, Name: "dup"}, FieldGetter("Id"), Ascending); i != 4 || is[4].Name == "dup" || len(is) != len(items()) {
+		t.Errorf("Equal item was inserted at %d instead of returning the existing one's index", i)
+	}
+	names := []string{"a", "b", "c"}
+	for n := 0; n < 2; n++ {
+		if i := SortedInsert(&names, "B", nil, CaseInsensitiveAscending); i != 1 {
+			t.Errorf("B was inserted at %d, not 1", i)
+		}
+	}
+	if c := []string{"a", "b", "c"}; !reflect.DeepEqual(names, c) {
+		t.Errorf("names are %v, not %v", names, c)
+	}
+	if i := SortedInsert(&names, "0", nil, Ascending); i != 0 || len(names) != 4 {
+		t.Errorf("0 was inserted at %d: %v", i, names)
+	}
+	ints := []int{9, 5, 1}
+	if i := SortedInsert(&ints, 4, nil, Descending); i != 2 {
+		t.Errorf("4 was inserted at %d, not 2", i)
+	}
+	if c := []int{9, 5, 4, 1}; !reflect.DeepEqual(ints, c) {
+		t.Errorf("ints are %v, not %v", ints, c)
+	}
+}
+
+func TestCompareFoldString(t *testing.T) {
+	strs := []string{"", "a", "A", "ab", "aB", "Ab", "b", "Z", "z[", "Z_", "\u00e9", "\u00c9t\u00e9", "\u00e9T", "\u03a3", "\u03c3a", "\xff", "a\xffb"}
+	sign := func(c int) int {
+		switch {
+		case c < 0:
+			return -1
+		case c > 0:
+			return 1
+		}
+		return 0
+	}
+	for _, a := range strs {
+		for _, b := range strs {
+			want := strings.Compare(strings.ToLower(a), strings.ToLower(b))
+			if got := compareFoldString(a, b); sign(got) != want {
+				t.Errorf("compareFoldString(%q, %q) is %d, not %d", a, b, got, want)
+			}
+		}
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		New(names(), nil, CaseInsensitiveDescending).Sort()
+	})
+	ref := testing.AllocsPerRun(100, func() {
+		New(names(), nil, Descending).Sort()
+	})
+	if allocs > ref {
+		t.Errorf("Case-insensitive descending sort allocates %v times, more than the %v of a descending sort", allocs, ref)
+	}
+}
+
+func TestSortWithTieBreak(t *testing.T) {
+	is := []Item{
+		{Id: 1, Name: "b"},
+		{Id: 2, Name: "a"},
+		{Id: 3, Name: "b"},
+		{Id: 4, Name: "a"},
+		{Id: 5, Name: "b"},
+	}
+	SortWithTieBreak(is, FieldGetter("Name"), Ascending, []int{3, 2, 1, 9, 2})
+	c := []int64{2, 4, 3, 5, 1}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	defer func() {
+		if x := recover(); x == nil {
+			t.Error("Mismatched priorities didn't cause a panic")
+		}
+	}()
+	SortWithTieBreak(is, FieldGetter("Name"), Ascending, []int{1, 2})
+}
+
+func TestSortByLess(t *testing.T) {
+	is := items()
+	calls := 0
+	key := func(elem reflect.Value) reflect.Value {
+		calls++
+		return reflect.ValueOf(elem.FieldByName("Id").Int() % 5)
+	}
+	less := func(a, b reflect.Value) bool {
+		return a.Int() < b.Int()
+	}
+	SortByLess(is, key, less)
+	if calls != len(is) {
+		t.Errorf("key was called %d times, not %d", calls, len(is))
+	}
+	for i := 1; i < len(is); i++ {
+		if is[i-1].Id%5 > is[i].Id%5 {
+			t.Errorf("Items aren't sorted by Id modulo 5: %v", is)
+			break
+		}
+	}
+}
+
+func TestAscBools(t *testing.T) {
+	bs := []bool{true, false, true, false, false}
+	Asc(bs)
+	if c := []bool{false, false, false, true, true}; !reflect.DeepEqual(bs, c) {
+		t.Errorf("Ascending bools are %v, not %v", bs, c)
+	}
+	Desc(bs)
+	if c := []bool{true, true, false, false, false}; !reflect.DeepEqual(bs, c) {
+		t.Errorf("Descending bools are %v, not %v", bs, c)
+	}
+	one := []bool{true}
+	Asc(one)
+	Asc([]bool{})
+}
+
+func TestAscByBoolField(t *testing.T) {
+	is := items()
+	AscByBoolField(is, "Valid")
+	for i, v := range is {
+		if v.Valid != (i >= 4) {
+			t.Errorf("is[%d].Valid is %v", i, v.Valid)
+		}
+	}
+	DescByBoolField(is, "Valid")
+	for i, v := range is {
+		if v.Valid != (i < 5) {
+			t.Errorf("is[%d].Valid is %v", i, v.Valid)
+		}
+	}
+}
+
+func TestSortNilSlices(t *testing.T) {
+	Asc([]int(nil))
+	Desc([]string(nil))
+	CiAsc([][]byte(nil))
+	Asc([]time.Time(nil))
+	AscByField([]Item(nil), "Id")
+	DescByField([]*Item(nil), "Date")
+	Asc((*[]int)(nil))
+	Asc((*[3]int)(nil))
+	Asc(nil)
+	Reverse(nil)
+	if p := ArgSort((*[]Item)(nil), FieldGetter("Id"), Ascending); len(p) != 0 {
+		t.Errorf("ArgSort of a nil pointer returned %v", p)
+	}
+	var ints []int
+	Asc(&ints)
+	if ints != nil {
+		t.Errorf("Sorting a nil slice made it non-nil: %v", ints)
+	}
+}
+
+func TestSortByKeyFunc(t *testing.T) {
+	is := items()
+	SortByKeyFunc(is, func(i int) interface{} { return is[i].Id }, Descending)
+	for i, v := range is {
+		if v.Id != int64(9-i) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, 9-i, v.Id)
+		}
+	}
+	ps := pointers()
+	SortByKeyFunc(ps, func(i int) interface{} {
+		if !ps[i].Valid {
+			return nil
+		}
+		return &ps[i].Name
+	}, Ascending)
+	for i, v := range ps {
+		if v.Valid != (i >= 4) {
+			t.Errorf("Invalid items aren't placed first: ps[%d] is %v", i, v)
+		}
+	}
+}
+
+func TestSorterSortStableReverse(t *testing.T) {
+	is := items()
+	New(is, FieldGetter("Valid"), Ascending).SortStableReverse()
+	c := []int64{6, 1, 9, 7, 4, 3, 2, 8, 5}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	ps := pointers()
+	New(ps, FieldGetter("Valid"), Descending).SortStableReverse()
+	c = []int64{3, 2, 8, 5, 6, 1, 9, 7, 4}
+	for i, v := range ps {
+		if v.Id != c[i] {
+			t.Errorf("ps[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ok := []struct {
+		slice    interface{}
+		getter   Getter
+		ordering Ordering
+	}{
+		{items(), FieldGetter("Id"), Descending},
+		{items(), FieldGetter("Name"), CaseInsensitiveAscending},
+		{pointers(), FieldGetter("Date"), Ascending},
+		{&[3]int{3, 1, 2}, nil, Ascending},
+		{[]interface{}{1, uint8(2)}, nil, Ascending},
+		{[]Item(nil), FieldGetter("Missing"), Ascending},
+		{records(), FieldGetter("Score"), Ascending},
+	}
+	for i, c := range ok {
+		if err := Validate(c.slice, c.getter, c.ordering); err != nil {
+			t.Errorf("Validate %d returned error: %v", i, err)
+		}
+	}
+	bad := []struct {
+		slice    interface{}
+		getter   Getter
+		ordering Ordering
+	}{
+		{items(), FieldGetter("Id"), Ordering(100)},
+		{3, nil, Ascending},
+		{[3]int{3, 1, 2}, nil, Ascending},
+		{items(), func(reflect.Value) []reflect.Value { return nil }, Ascending},
+		{items(), FieldGetter("Date"), CaseInsensitiveAscending},
+		{testStructs(), FieldGetter("Invalid"), Ascending},
+		{[]interface{}{1, "a"}, nil, Ascending},
+	}
+	for i, c := range bad {
+		if err := Validate(c.slice, c.getter, c.ordering); err == nil {
+			t.Errorf("Validate %d didn't return an error", i)
+		}
+	}
+	if err := ValidateField(items(), "Name", Ascending); err != nil {
+		t.Errorf("ValidateField returned error: %v", err)
+	}
+	for _, name := range []string{"Missing", "unexported"} {
+		if err := ValidateField(testStructs(), name, Ascending); err == nil {
+			t.Errorf("ValidateField of field %s didn't return an error", name)
+		}
+	}
+	if err := ValidateField([]int{1}, "Id", Ascending); err == nil {
+		t.Error("ValidateField of a non-struct slice didn't return an error")
+	}
+}
+
+func TestGroupByField(t *testing.T) {
+	is := items()
+	groups := GroupByField(is, "Valid").([][]Item)
+	if len(groups) != 2 {
+		t.Fatalf("Got %d groups, not 2", len(groups))
+	}
+	for g, valid := range []bool{false, true} {
+		for _, v := range groups[g] {
+			if v.Valid != valid {
+				t.Errorf("Item %d in group %d has Valid %v", v.Id, g, v.Valid)
+			}
+		}
+	}
+	if len(groups[0]) != 4 || len(groups[1]) != 5 {
+		t.Errorf("Groups have %d and %d items, not 4 and 5", len(groups[0]), len(groups[1]))
+	}
+	if groups[0][0].Id != is[0].Id {
+		t.Error("Groups aren't in the order of the sorted slice")
+	}
+	if len(GroupByField([]Item{}, "Valid").([][]Item)) != 0 {
+		t.Error("Grouping an empty slice returned groups")
+	}
+}
+
+func TestInsertionSort(t *testing.T) {
+	// Compare sorting short slices with and without insertion sort
+	defer func(n int) {
+		insertionSortThreshold = n
+	}(insertionSortThreshold)
+	for _, o := range []Ordering{Ascending, Descending, DescendingStableTies} {
+		insertionSortThreshold = 12
+		is := items()
+		Sort(is, FieldGetter("Valid"), o)
+		insertionSortThreshold = 0
+		ref := items()
+		Sort(ref, FieldGetter("Valid"), o)
+		for i := range is {
+			if is[i].Valid != ref[i].Valid {
+				t.Errorf("%v: is[%d].Valid is not %v", o, i, ref[i].Valid)
+			}
+			if o == DescendingStableTies && is[i].Id != ref[i].Id {
+				t.Errorf("%v: is[%d].Id is not %d, but %d", o, i, ref[i].Id, is[i].Id)
+			}
+		}
+	}
+	insertionSortThreshold = 12
+	ints := []int{5, 3, 8, 1, 9, 2, 7, 2}
+	Asc(ints)
+	if c := []int{1, 2, 2, 3, 5, 7, 8, 9}; !reflect.DeepEqual(ints, c) {
+		t.Errorf("ints are %v, not %v", ints, c)
+	}
+}
+
+// Compares strings by the positions of their letters in an alphabet, like a
+// collate.Collator for a language with its own alphabetical order.
+type alphabetCollator string
+
+func (c alphabetCollator) CompareString(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	for i := 0; i < len(x) && i < len(y); i++ {
+		p, q := strings.IndexRune(string(c), x[i]), strings.IndexRune(string(c), y[i])
+		if p != q {
+			if p < q {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(x) < len(y):
+		return -1
+	case len(x) > len(y):
+		return 1
+	}
+	return 0
+}
+
+// The Swedish alphabet, in which å, ä and ö follow z.
+const swedish = alphabetCollator("abcdefghijklmnopqrstuvwxyz\u00e5\u00e4\u00f6")
+
+func TestAscByFieldCollated(t *testing.T) {
+	is := []Item{{Name: "\u00f6l"}, {Name: "zon"}, {Name: "\u00e5r"}, {Name: "alm"}, {Name: "\u00e4ng"}}
+	AscByFieldCollated(is, "Name", swedish)
+	c := []string{"alm", "zon", "\u00e5r", "\u00e4ng", "\u00f6l"}
+	for i, v := range is {
+		if v.Name != c[i] {
+			t.Errorf("is[%d].Name is not %q, but %q", i, c[i], v.Name)
+		}
+	}
+	DescByFieldCollated(is, "Name", swedish)
+	for i, v := range is {
+		if v.Name != c[len(c)-1-i] {
+			t.Errorf("is[%d].Name is not %q, but %q", i, c[len(c)-1-i], v.Name)
+		}
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	cases := []struct {
+		getter Getter
+		kind   reflect.Kind
+		typ    reflect.Type
+	}{
+		{FieldGetter("Id"), reflect.Int64, reflect.TypeOf(int64(0))},
+		{FieldGetter("Name"), reflect.String, reflect.TypeOf("")},
+		{FieldGetter("Date"), reflect.Struct, reflect.TypeOf(time.Time{})},
+	}
+	for _, c := range cases {
+		k, typ, err := DetectKind(pointers(), c.getter)
+		if err != nil {
+			t.Errorf("DetectKind returned error: %v", err)
+			continue
+		}
+		if k != c.kind || typ != c.typ {
+			t.Errorf("DetectKind returned %v %v, not %v %v", k, typ, c.kind, c.typ)
+		}
+	}
+	if k, _, err := DetectKind(records(), FieldGetter("Score")); err != nil || k != reflect.Int {
+		t.Errorf("DetectKind of pointer field returned %v, %v", k, err)
+	}
+	if _, _, err := DetectKind(testStructs(), FieldGetter("Invalid")); err == nil {
+		t.Error("DetectKind of an unsupported field didn't return an error")
+	}
+	if _, _, err := DetectKind([]Item{}, FieldGetter("Id")); err == nil {
+		t.Error("DetectKind of an empty slice didn't return an error")
+	}
+}
+
+type Answer struct {
+	Id  int
+	Yes *bool
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestSortByBoolPointerField(t *testing.T) {
+	answers := func() []Answer {
+		return []Answer{
+			{1, boolPtr(true)},
+			{2, nil},
+			{3, boolPtr(false)},
+			{4, boolPtr(true)},
+			{5, nil},
+			{6, boolPtr(false)},
+		}
+	}
+	// "n" is nil (unknown), "f" false and "t" true
+	cases := []struct {
+		ordering Ordering
+		policy   Policy
+		want     string
+	}{
+		{Ascending, Policy{}, "nnfftt"},
+		{Descending, Policy{}, "ttffnn"},
+		{Ascending, Policy{Nils: First}, "nnfftt"},
+		{Ascending, Policy{Nils: Last}, "ffttnn"},
+		{Descending, Policy{Nils: First}, "nnttff"},
+		{Descending, Policy{Nils: Last}, "ttffnn"},
+	}
+	for _, c := range cases {
+		as := answers()
+		s := New(as, FieldGetter("Yes"), c.ordering)
+		s.Policy = c.policy
+		s.Sort()
+		got := ""
+		for _, a := range as {
+			switch {
+			case a.Yes == nil:
+				got += "n"
+			case *a.Yes:
+				got += "t"
+			default:
+				got += "f"
+			}
+		}
+		if got != c.want {
+			t.Errorf("Sorting by *bool in %v order with %+v gave %s, not %s", c.ordering, c.policy, got, c.want)
+		}
+	}
+}
+
+func TestRestoreOrder(t *testing.T) {
+	is := items()
+	o := CaptureOrder(is, FieldGetter("Id"))
+	DescByField(is, "Name")
+	AscByField(is, "Date")
+	RestoreOrder(is, o)
+	if !reflect.DeepEqual(is, items()) {
+		t.Errorf("Restored items are %v, not %v", is, items())
+	}
+	// A subset with an element that wasn't captured
+	ints := []int{3, 1, 3, 2}
+	o = CaptureOrder(ints, nil)
+	sub := []int{2, 7, 3, 3}
+	RestoreOrder(sub, o)
+	if c := []int{3, 3, 2, 7}; !reflect.DeepEqual(sub, c) {
+		t.Errorf("Restored ints are %v, not %v", sub, c)
+	}
+	ps := pointers()
+	o = CaptureOrder(ps, nil)
+	sub2 := []*Item{ps[8], ps[0], ps[4]}
+	RestoreOrder(sub2, o)
+	if sub2[0] != ps[0] || sub2[1] != ps[4] || sub2[2] != ps[8] {
+		t.Errorf("Restored pointers are in the wrong order: %v", sub2)
+	}
+}
+
+func TestMultiSortPolicies(t *testing.T) {
+	rs := []Record{
+		{Id: 1, Name: "b", Score: intPtr(2)},
+		{Id: 2, Name: "", Score: intPtr(1)},
+		{Id: 3, Name: "a", Score: nil},
+		{Id: 4, Name: "b", Score: nil},
+		{Id: 5, Name: "a", Score: intPtr(3)},
+		{Id: 6, Name: "", Score: nil},
+	}
+	MultiSort(rs,
+		KeySpec{Getter: FieldGetter("Name"), Policy: Policy{Empties: Last}},
+		KeySpec{Getter: FieldGetter("Score"), Ordering: Descending, Policy: Policy{Nils: First}},
+	)
+	if ids, c := recordIds(rs), []int{3, 5, 4, 1, 6, 2}; !reflect.DeepEqual(ids, c) {
+		t.Errorf("Records sorted by Name with empties last, then by Score with nils first: ids are %v, not %v", ids, c)
+	}
+	built := Build(rs).ByField("Score").Descending().WithPolicy(Policy{Nils: Last}).Keys()
+	if len(built) != 1 || built[0].Policy.Nils != Last || built[0].Ordering != Descending {
+		t.Errorf("Builder keys are %+v", built)
+	}
+	defer func() {
+		if x := recover(); x == nil {
+			t.Error("A nil value with an AsError policy didn't cause a panic")
+		}
+	}()
+	MultiSort(rs,
+		KeySpec{Getter: FieldGetter("Name")},
+		KeySpec{Getter: FieldGetter("Score"), Policy: Policy{Nils: AsError}},
+	)
+}
+
+func TestIsStableSort(t *testing.T) {
+	// Items sorted by Valid, identified by their original indices
+	is := items()
+	orig := make(map[int64]int)
+	for i, v := range is {
+		orig[v.Id] = i
+	}
+	original := func(i int) int {
+		return orig[is[i].Id]
+	}
+	sort.Stable(SortableByValid(is))
+	if !IsStableSort(SortableByValid(is), original) {
+		t.Error("sort.Stable result wasn't reported as stable")
+	}
+	// Swapping two equal elements makes the result unstable
+	is[0], is[1] = is[1], is[0]
+	if IsStableSort(SortableByValid(is), original) {
+		t.Error("Result with swapped equal elements was reported as stable")
+	}
+	// Reversing the sorted order makes the result unsorted
+	SortReverseInterface(SortableByValid(is))
+	if IsStableSort(SortableByValid(is), original) {
+		t.Error("Unsorted result was reported as stable")
+	}
+}
+
+type Child struct {
+	Id int
+}
+
+type Parent struct {
+	Name     string
+	Children []Child
+}
+
+func TestChildGetter(t *testing.T) {
+	ps := []Parent{
+		{"a", []Child{{5}, {3}, {8}}},
+		{"b", []Child{{4}, {9}}},
+		{"c", nil},
+		{"d", []Child{{1}, {2}}},
+		{"e", []Child{{7}}},
+	}
+	Sort(ps, ChildGetter("Children", FieldGetter("Id"), ReduceMin), Ascending)
+	c := "cdabe"
+	for i, v := range ps {
+		if v.Name != c[i:i+1] {
+			t.Errorf("ps[%d].Name is not %s, but %s", i, c[i:i+1], v.Name)
+		}
+	}
+	Sort(ps, ChildGetter("Children", FieldGetter("Id"), ReduceMax), Descending)
+	c = "baedc"
+	for i, v := range ps {
+		if v.Name != c[i:i+1] {
+			t.Errorf("ps[%d].Name is not %s, but %s", i, c[i:i+1], v.Name)
+		}
+	}
+	ints := [][]int{{3, 9}, {5, 4}, {1, 8}}
+	Sort(ints, ChildGetter("", nil, ReduceMax), Ascending)
+	if c := [][]int{{5, 4}, {1, 8}, {3, 9}}; !reflect.DeepEqual(ints, c) {
+		t.Errorf("ints are %v, not %v", ints, c)
+	}
+}
+
+func TestSortPanicMessage(t *testing.T) {
+	expect := func(slice interface{}, parts ...string) {
+		defer func() {
+			x := recover()
+			if x == nil {
+				t.Errorf("Sorting %v didn't cause a panic", slice)
+				return
+			}
+			msg := fmt.Sprint(x)
+			for _, p := range parts {
+				if !strings.Contains(msg, p) {
+					t.Errorf("Panic message %q doesn't contain %q", msg, p)
+				}
+			}
+		}()
+		Asc(slice)
+	}
+	expect([]interface{}{3, 1, 2, "four", 5}, "four", "index 3", "string")
+	expect([]interface{}{nil, InvalidType{"foo", 123}, 1}, "foo", "index 1", "InvalidType")
+	// Compatible values of different types are still sorted
+	mixed := []interface{}{int8(3), 1, uint(2)}
+	Asc(mixed)
+	if fmt.Sprint(mixed) != "[1 2 3]" {
+		t.Errorf("Mixed integers are %v", mixed)
+	}
+}
+
+type Version struct {
+	Major, Minor int
+}
+
+type Package struct {
+	Name    string
+	Version Version
+}
+
+func compareVersions(a, b reflect.Value) int {
+	x, y := a.Interface().(Version), b.Interface().(Version)
+	switch {
+	case x.Major != y.Major:
+		if x.Major < y.Major {
+			return -1
+		}
+		return 1
+	case x.Minor < y.Minor:
+		return -1
+	case x.Minor > y.Minor:
+		return 1
+	}
+	return 0
+}
+
+func TestAscByFieldCmp(t *testing.T) {
+	ps := []Package{
+		{"c", Version{1, 10}},
+		{"a", Version{2, 0}},
+		{"d", Version{1, 2}},
+		{"b", Version{0, 9}},
+	}
+	AscByFieldCmp(ps, "Version", compareVersions)
+	c := "bdca"
+	for i, v := range ps {
+		if v.Name != c[i:i+1] {
+			t.Errorf("ps[%d].Name is not %s, but %s", i, c[i:i+1], v.Name)
+		}
+	}
+	DescByFieldCmp(ps, "Version", compareVersions)
+	c = "acdb"
+	for i, v := range ps {
+		if v.Name != c[i:i+1] {
+			t.Errorf("ps[%d].Name is not %s, but %s", i, c[i:i+1], v.Name)
+		}
+	}
+}
+
+type Deployment struct {
+	Name    string
+	Version *Version
+}
+
+func TestRegisterType(t *testing.T) {
+	vt := reflect.TypeOf(Version{})
+	RegisterType(vt, compareVersions)
+	defer RegisterType(vt, nil)
+	ps := []Package{
+		{"c", Version{1, 10}},
+		{"a", Version{2, 0}},
+		{"b", Version{0, 9}},
+	}
+	AscByField(ps, "Version")
+	if ps[0].Name != "b" || ps[1].Name != "c" || ps[2].Name != "a" {
+		t.Errorf("Packages sorted by registered Version type: %v", ps)
+	}
+	bs := []Deployment{
+		{"x", &Version{3, 1}},
+		{"y", nil},
+		{"z", &Version{3, 0}},
+	}
+	DescByField(bs, "Version")
+	if bs[0].Name != "x" || bs[1].Name != "z" || bs[2].Name != "y" {
+		t.Errorf("Deployments sorted by registered *Version type: %v", bs)
+	}
+}
+
+func TestBucketByRanges(t *testing.T) {
+	ints := []int{25, 3, 10, 31, 19, 20, 7, 30, 12}
+	buckets := BucketByRanges(ints, nil, []interface{}{10, 20, 30}).([][]int)
+	c := [][]int{{3, 7}, {10, 12, 19}, {20, 25}, {30, 31}}
+	if !reflect.DeepEqual(buckets, c) {
+		t.Errorf("Buckets are %v, not %v", buckets, c)
+	}
+	buckets = BucketByRanges([]int{1, 2}, nil, []interface{}{10, 20}).([][]int)
+	if len(buckets) != 3 || len(buckets[0]) != 2 || len(buckets[1]) != 0 || len(buckets[2]) != 0 {
+		t.Errorf("Buckets are %v", buckets)
+	}
+	is := BucketByRanges(items(), FieldGetter("Id"), []interface{}{int64(5)}).([][]Item)
+	if len(is) != 2 || len(is[0]) != 4 || len(is[1]) != 5 || is[1][0].Id != 5 {
+		t.Errorf("Items bucketed by Id: %v", is)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("No panic for edges that aren't sorted")
+		}
+	}()
+	BucketByRanges(ints, nil, []interface{}{20, 10})
+}
+
+type Payment struct {
+	Id     int
+	Amount string `json:"amount"`
+}
+
+func TestJSONTagNumericGetter(t *testing.T) {
+	ps := []Payment{{1, "10"}, {2, "2"}, {3, " 2.5"}, {4, "n/a"}, {5, "-1"}}
+	s := New(ps, JSONTagNumericGetter("amount"), Ascending)
+	s.Policy.NaNs = Last
+	s.Sort()
+	c := []int{5, 2, 3, 1, 4}
+	for i, v := range ps {
+		if v.Id != c[i] {
+			t.Errorf("ps[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	Sort(ps, JSONTagGetter("amount"), Ascending)
+	if ps[0].Id != 3 || ps[2].Id != 1 {
+		t.Errorf("Payments sorted by amount as strings: %v", ps)
+	}
+}
+
+func TestAscByFieldThenReverse(t *testing.T) {
+	is := items()
+	is[3].Name = is[0].Name
+	is[5].Name = is[0].Name
+	AscByFieldThenReverse(is, "Name")
+	// The items named d are in the reverse of their original order, 6, 3, 2
+	c := []int64{7, 4, 5, 2, 3, 6, 1, 9, 8}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+type Point struct {
+	X, Y int
+}
+
+type Shape struct {
+	Name   string
+	Origin Point
+}
+
+func TestTextualNumericGetter(t *testing.T) {
+	ints := []int{20, 100, 3, 1000}
+	Sort(ints, TextualNumericGetter(nil, ""), Ascending)
+	if c := []int{100, 1000, 20, 3}; !reflect.DeepEqual(ints, c) {
+		t.Errorf("Ints sorted as text are %v, not %v", ints, c)
+	}
+	Sort(ints, TextualNumericGetter(nil, "%05d"), Ascending)
+	if c := []int{3, 20, 100, 1000}; !reflect.DeepEqual(ints, c) {
+		t.Errorf("Ints sorted as zero-padded text are %v, not %v", ints, c)
+	}
+	fs := []float64{2.5, 10, -1}
+	Sort(fs, TextualNumericGetter(nil, "%.1f"), Descending)
+	if c := []float64{2.5, 10, -1}; !reflect.DeepEqual(fs, c) {
+		t.Errorf("Floats sorted as text in descending order are %v, not %v", fs, c)
+	}
+}
+
+func TestReprGetter(t *testing.T) {
+	ss := []Shape{
+		{"a", Point{2, 1}},
+		{"b", Point{1, 5}},
+		{"c", Point{2, 0}},
+		{"d", Point{1, 3}},
+	}
+	Sort(ss, ReprGetter(FieldGetter("Origin")), Ascending)
+	c := "dbca"
+	for i, s := range ss {
+		if s.Name != c[i:i+1] {
+			t.Errorf("ss[%d].Name is not %s, but %s", i, c[i:i+1], s.Name)
+		}
+	}
+	ps := []Point{{3, 4}, {1, 2}, {3, 1}}
+	Sort(ps, ReprGetter(nil), Descending)
+	if c := []Point{{3, 4}, {3, 1}, {1, 2}}; !reflect.DeepEqual(ps, c) {
+		t.Errorf("Points are %v, not %v", ps, c)
+	}
+}
+
+func TestSortTieShuffle(t *testing.T) {
+	is := items()
+	for i := range is {
+		is[i].Id /= 3
+	}
+	a := append([]Item(nil), is...)
+	b := append([]Item(nil), is...)
+	SortTieShuffle(a, FieldGetter("Id"), Ascending, 42)
+	SortTieShuffle(b, FieldGetter("Id"), Ascending, 42)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Items shuffled with the same seed are in different orders:\n%v\n%v", a, b)
+	}
+	if d := DiffFromSorted(a, FieldGetter("Id"), Ascending); len(d) != 0 {
+		t.Errorf("Shuffled items aren't sorted at %v", d)
+	}
+	shuffled := false
+	for seed := int64(0); seed < 10 && !shuffled; seed++ {
+		c := append([]Item(nil), is...)
+		SortTieShuffle(c, FieldGetter("Id"), Ascending, seed)
+		shuffled = !reflect.DeepEqual(a, c)
+	}
+	if !shuffled {
+		t.Error("Items shuffled with different seeds are always in the same order")
+	}
+}
+
+type Contact struct {
+	Name    string
+	Email   string
+	Phone   *string
+	Age     int
+	Tags    []string
+	Address Point
+	note    string
+}
+
+func TestRanksByField(t *testing.T) {
+	is := items()
+	is[1].Id = 9
+	is[4].Id = 4
+	// Ids are 6, 9, 9, 3, 4, 2, 8, 5, 4
+	ranks := RanksByField(is, "Id", Descending, StandardRanking)
+	if c := []int{4, 1, 1, 8, 6, 9, 3, 5, 6}; !reflect.DeepEqual(ranks, c) {
+		t.Errorf("Standard ranks are %v, not %v", ranks, c)
+	}
+	ranks = RanksByField(is, "Id", Descending, DenseRanking)
+	if c := []int{3, 1, 1, 6, 5, 7, 2, 4, 5}; !reflect.DeepEqual(ranks, c) {
+		t.Errorf("Dense ranks are %v, not %v", ranks, c)
+	}
+	ranks = RanksByField(is, "Id", Ascending, StandardRanking)
+	if c := []int{6, 8, 8, 2, 3, 1, 7, 5, 3}; !reflect.DeepEqual(ranks, c) {
+		t.Errorf("Ascending standard ranks are %v, not %v", ranks, c)
+	}
+	if is[1].Id != 9 || is[3].Id != 3 {
+		t.Error("RanksByField reordered the slice")
+	}
+	if ranks := RanksByField([]Item{}, "Id", Ascending, DenseRanking); len(ranks) != 0 {
+		t.Errorf("Ranks of no items are %v", ranks)
+	}
+}
+
+func TestSortCollapseSpace(t *testing.T) {
+	ss := []string{"bob ", "carol", " bob", "al\tice", "bob", " al ice", "alice"}
+	s := New(ss, nil, Ascending)
+	s.Normalize = CollapseSpace
+	s.Sort()
+	for i := 0; i < 2; i++ {
+		if CollapseSpace(ss[i]) != "al ice" {
+			t.Errorf("ss[%d] is not al ice, but %q", i, ss[i])
+		}
+	}
+	if ss[2] != "alice" || ss[6] != "carol" {
+		t.Errorf("Strings with collapsed whitespace are not sorted: %q", ss)
+	}
+	for i := 3; i < 6; i++ {
+		if strings.TrimSpace(ss[i]) != "bob" {
+			t.Errorf("ss[%d] is not bob, but %q", i, ss[i])
+		}
+	}
+	s.setup()
+	l := s.lesser()
+	if l.Less(3, 4) || l.Less(4, 3) || l.Less(4, 5) || l.Less(5, 4) {
+		t.Error("Names differing only in whitespace don't compare equal")
+	}
+}
+
+func TestAdaptiveSort(t *testing.T) {
+	check := func(name string, is []*Item) {
+		AscByField(is, "Id")
+		for i := 1; i < len(is); i++ {
+			if is[i].Id < is[i-1].Id {
+				t.Errorf("%s: is[%d].Id %d is less than is[%d].Id %d", name, i, is[i].Id, i-1, is[i-1].Id)
+				return
+			}
+		}
+	}
+	check("nearly sorted", nearlySortedPointers(1000))
+	// Too far from sorted for insertion sort
+	is := nearlySortedPointers(1000)
+	for i := range is {
+		is[i].Id = int64((i * 7919) % 1000)
+	}
+	check("shuffled", is)
+	is = nearlySortedPointers(1000)
+	is[0].Id = 5000
+	check("one far out of place", is)
+	s := New(nearlySortedPointers(1000), FieldGetter("Id"), Ascending)
+	s.setup()
+	adaptiveSort(s.lesser())
+	// Each of the 20 out of place pointers moves, along with the 4 after it,
+	// and ties keep their order
+	if n := moved(s.order()); n != 100 {
+		t.Errorf("Sorting nearly sorted pointers moved %d elements, not 100", n)
+	}
+}
+
+func TestAscByCompleteness(t *testing.T) {
+	phone := "555-0100"
+	cs := []Contact{
+		{Name: "b", Email: "b@example.com", Age: 30},
+		{Name: "a", note: "unexported fields don't count"},
+		{Name: "d", Email: "d@example.com", Phone: &phone, Age: 40, Tags: []string{"x"}, Address: Point{1, 0}},
+		{Name: "c", Email: "c@example.com", Phone: &phone, Tags: []string{}},
+	}
+	AscByCompleteness(cs)
+	c := "abcd"
+	for i, v := range cs {
+		if v.Name != c[i:i+1] {
+			t.Errorf("cs[%d].Name is not %s, but %s", i, c[i:i+1], v.Name)
+		}
+	}
+	DescByCompleteness(cs)
+	if cs[0].Name != "d" || cs[3].Name != "a" {
+		t.Errorf("Contacts sorted by descending completeness: %v", cs)
+	}
+}
+
+func TestEnsureOrderingCompatible(t *testing.T) {
+	is := items()
+	if err := EnsureOrderingCompatible(is, FieldGetter("Id"), CaseInsensitiveAscending); err == nil {
+		t.Error("No error for a case-insensitive ordering of an int field")
+	}
+	if err := EnsureOrderingCompatible(is, FieldGetter("Id"), Descending); err != nil {
+		t.Errorf("Error for a descending ordering of an int field: %v", err)
+	}
+	if err := EnsureOrderingCompatible(is, FieldGetter("Name"), CaseInsensitiveDescending); err != nil {
+		t.Errorf("Error for a case-insensitive ordering of a string field: %v", err)
+	}
+	if err := EnsureOrderingCompatible(lines(), FieldGetter("Text"), CaseInsensitiveAscending); err != nil {
+		t.Errorf("Error for a case-insensitive ordering of a []rune field: %v", err)
+	}
+	if err := EnsureOrderingCompatible([]int{}, nil, CaseInsensitiveAscending); err != nil {
+		t.Errorf("Error for a case-insensitive ordering of an empty slice: %v", err)
+	}
+}
+
+func TestDiffFromSorted(t *testing.T) {
+	is := items()
+	AscByField(is, "Id")
+	if d := DiffFromSorted(is, FieldGetter("Id"), Ascending); len(d) != 0 {
+		t.Errorf("Sorted items differ at %v", d)
+	}
+	is[2], is[6] = is[6], is[2]
+	orig := append([]Item(nil), is...)
+	if d := DiffFromSorted(is, FieldGetter("Id"), Ascending); !reflect.DeepEqual(d, []int{2, 6}) {
+		t.Errorf("Items with two swapped differ at %v, not [2 6]", d)
+	}
+	if !reflect.DeepEqual(is, orig) {
+		t.Error("DiffFromSorted modified the slice")
+	}
+	ints := []int{1, 2, 3, 4}
+	if d := DiffFromSorted(ints, nil, Descending); !reflect.DeepEqual(d, []int{0, 1, 2, 3}) {
+		t.Errorf("Ascending ints differ from descending order at %v", d)
+	}
+	// Equal values in a different order aren't a difference
+	bs := []bool{true, false, true, false}
+	if d := DiffFromSorted(bs, nil, Ascending); !reflect.DeepEqual(d, []int{0, 3}) {
+		t.Errorf("Bools differ from ascending order at %v, not [0 3]", d)
+	}
+}
+
+type Ambiguous struct {
+	ID int
+	Id int
+}
+
+func TestAscByFieldCI(t *testing.T) {
+	is := items()
+	AscByFieldCI(is, "name")
+	for i, v := range names() {
+		if is[i].Name != v {
+			t.Errorf("is[%d].Name is not %s, but %s", i, v, is[i].Name)
+		}
+	}
+	DescByFieldCI(is, "ID")
+	if is[0].Id != 9 || is[len(is)-1].Id != 1 {
+		t.Errorf("Items sorted by ID: %v", is)
+	}
+	as := []Ambiguous{{1, 2}, {2, 1}}
+	AscByFieldCI(as, "Id")
+	if as[0].Id != 1 {
+		t.Errorf("Exact field name match wasn't preferred: %v", as)
+	}
+	defer func() {
+		if x := recover(); x == nil || !strings.Contains(fmt.Sprint(x), "ambiguous") {
+			t.Errorf("Panic for an ambiguous field name was %v", x)
+		}
+	}()
+	AscByFieldCI(as, "id")
+}
+
+func TestDistinctByField(t *testing.T) {
+	is := items()
+	is = append(is, is[3], is[0], is[3])
+	is[1].Name = "Foo"
+	is[2].Name = "foo"
+	orig := append([]Item(nil), is...)
+	names := DistinctByField(is, "Name", Ascending)
+	if len(names) != 9 {
+		t.Errorf("Got %d distinct names, not 9: %v", len(names), names)
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1].(string) >= names[i].(string) {
+			t.Errorf("Distinct names aren't sorted: %v", names)
+		}
+	}
+	if !reflect.DeepEqual(is, orig) {
+		t.Error("DistinctByField modified the slice")
+	}
+	names = DistinctByField(is, "Name", CaseInsensitiveDescending)
+	if len(names) != 7 {
+		t.Errorf("Got %d case-insensitively distinct names, not 7: %v", len(names), names)
+	}
+	valid := DistinctByField(is, "Valid", Descending)
+	if !reflect.DeepEqual(valid, []interface{}{true, false}) {
+		t.Errorf("Distinct Valid values are %v", valid)
+	}
+	if n := DistinctByField([]Item{}, "Name", Ascending); len(n) != 0 {
+		t.Errorf("Distinct names of no items are %v", n)
+	}
+}
+
+func TestRegisterTypeDescending(t *testing.T) {
+	vt := reflect.TypeOf(Version{})
+	RegisterType(vt, compareVersions)
+	defer RegisterType(vt, nil)
+	ps := []Package{
+		{"c", Version{1, 10}},
+		{"a", Version{2, 0}},
+		{"d", Version{1, 10}},
+		{"b", Version{0, 9}},
+		{"e", Version{2, 0}},
+	}
+	Sort(ps, FieldGetter("Version"), DescendingStableTies)
+	c := "aecdb"
+	for i, p := range ps {
+		if p.Name != c[i:i+1] {
+			t.Errorf("ps[%d].Name is not %s, but %s", i, c[i:i+1], p.Name)
+		}
+	}
+	DescByField(ps, "Version")
+	for i := 1; i < len(ps); i++ {
+		if compareVersions(reflect.ValueOf(ps[i-1].Version), reflect.ValueOf(ps[i].Version)) < 0 {
+			t.Errorf("Packages aren't in descending order: %v", ps)
+		}
+	}
+}
+
+func TestSortCompact(t *testing.T) {
+	is := items()
+	is[2].Id = 0
+	is[5].Id = 0
+	SortCompact(&is, FieldGetter("Id"), Descending)
+	c := []int64{8, 7, 6, 5, 4, 3, 1}
+	if len(is) != len(c) {
+		t.Fatalf("Compacted slice has %d items, not %d", len(is), len(c))
+	}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	if cleared := is[:cap(is)][len(c)]; cleared.Id != 0 || cleared.Name != "" {
+		t.Errorf("Element past the new length wasn't cleared: %v", cleared)
+	}
+	ps := pointers()
+	ps[0], ps[4], ps[8] = nil, nil, nil
+	ps[2].Id = 0
+	SortCompact(&ps, FieldGetter("Id"), Ascending)
+	c = []int64{1, 2, 3, 5, 8}
+	if len(ps) != len(c) {
+		t.Fatalf("Compacted pointers have %d items, not %d", len(ps), len(c))
+	}
+	for i, v := range ps {
+		if v.Id != c[i] {
+			t.Errorf("ps[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	ints := []int{3, 0, 1, 0, 2}
+	SortCompact(&ints, nil, Descending)
+	if c := []int{3, 2, 1}; !reflect.DeepEqual(ints, c) {
+		t.Errorf("Compacted ints are %v, not %v", ints, c)
+	}
+	var empty []int
+	SortCompact(&empty, nil, Ascending)
+}
+
+type Names []string
+
+func TestSortNamedSliceTypes(t *testing.T) {
+	var is interface{} = SortableItems(items())
+	AscByField(is, "Id")
+	for i, v := range is.(SortableItems) {
+		if v.Id != int64(i+1) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+		}
+	}
+	ps := SortablePointers(pointers())
+	DescByField(&ps, "Id")
+	for i, v := range ps {
+		if v.Id != int64(9-i) {
+			t.Errorf("ps[%d].Id is not %d, but %d", i, 9-i, v.Id)
+		}
+	}
+	ns := Names{"c", "A", "b"}
+	CiAsc(ns)
+	if c := (Names{"A", "b", "c"}); !reflect.DeepEqual(ns, c) {
+		t.Errorf("Names are %v, not %v", ns, c)
+	}
+	Reverse(ns)
+	if c := (Names{"c", "b", "A"}); !reflect.DeepEqual(ns, c) {
+		t.Errorf("Reversed names are %v, not %v", ns, c)
+	}
+	if top := TopN(ns, nil, Ascending, 2).(Names); !reflect.DeepEqual(top, Names{"A", "b"}) {
+		t.Errorf("Top names are %v", top)
+	}
+	merged := MergeSorted(Ascending, nil, Names{"a", "c"}, Names{"b"}).(Names)
+	if !reflect.DeepEqual(merged, Names{"a", "b", "c"}) {
+		t.Errorf("Merged names are %v", merged)
+	}
+	SortedInsert(&ns, "a", nil, Descending)
+	if c := (Names{"c", "b", "a", "A"}); !reflect.DeepEqual(ns, c) {
+		t.Errorf("Names after insert are %v, not %v", ns, c)
+	}
+	groups := GroupByField(SortableItems(items()), "Valid").([]SortableItems)
+	if len(groups) != 2 {
+		t.Errorf("Got %d groups, not 2", len(groups))
+	}
+}
+
+func TestMultiSortEqualTimesInDifferentLocations(t *testing.T) {
+	instant := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	tokyo := time.FixedZone("JST", 9*60*60)
+	is := []Item{
+		{Id: 3, Date: instant},
+		{Id: 1, Date: instant.In(tokyo)},
+		{Id: 4, Date: instant.Add(-time.Hour)},
+		{Id: 2, Date: instant.In(time.Local)},
+	}
+	MultiSort(is,
+		KeySpec{Getter: FieldGetter("Date")},
+		KeySpec{Getter: FieldGetter("Id")},
+	)
+	c := []int64{4, 1, 2, 3}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	if Compare(instant, instant.In(tokyo), Ascending) != 0 {
+		t.Error("Equal instants in different locations don't compare equal")
+	}
+}
+
+type Status int
+
+const (
+	Pending Status = iota
+	Active
+	Zombie
+	Archived
+)
+
+// The names of the statuses sort differently from their values.
+func (s Status) String() string {
+	return [...]string{"pending", "active", "zombie", "archived"}[s]
+}
+
+type Task struct {
+	Id     int
+	Status Status
+}
+
+func TestAscByFieldStringerEnum(t *testing.T) {
+	ts := []Task{{1, Archived}, {2, Pending}, {3, Zombie}, {4, Active}}
+	AscByField(ts, "Status")
+	c := []Status{Pending, Active, Zombie, Archived}
+	for i, v := range ts {
+		if v.Status != c[i] {
+			t.Errorf("ts[%d].Status is not %v, but %v", i, c[i], v.Status)
+		}
+	}
+	ss := []Status{Zombie, Pending, Archived}
+	Desc(ss)
+	if c := []Status{Archived, Zombie, Pending}; !reflect.DeepEqual(ss, c) {
+		t.Errorf("Statuses are %v, not %v", ss, c)
+	}
+	defer func() {
+		x := recover()
+		if msg, _ := x.(string); !strings.Contains(msg, "sortutil.Status") || !strings.Contains(msg, "case-insensitive") {
+			t.Errorf("Unclear panic message: %v", x)
+		}
+	}()
+	CiAscByField(ts, "Status")
+}
+
+func TestMultiSortBool(t *testing.T) {
+	is := items()
+	MultiSort(is,
+		KeySpec{Getter: FieldGetter("Valid")},
+		KeySpec{Getter: FieldGetter("Id")},
+	)
+	c := []int64{2, 3, 5, 8, 1, 4, 6, 7, 9}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+	// True first
+	Build(is).ByField("Valid").Descending().ByField("Id").Descending().Do()
+	c = []int64{9, 7, 6, 4, 1, 8, 5, 3, 2}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestSortChannel(t *testing.T) {
+	in := make(chan interface{})
+	go func() {
+		for _, v := range []int{5, 2, 8, 1, 9, 3} {
+			in <- v
+		}
+		close(in)
+	}()
+	var got []int
+	for v := range SortChannel(in, nil, Ascending) {
+		got = append(got, v.(int))
+	}
+	if c := []int{1, 2, 3, 5, 8, 9}; !reflect.DeepEqual(got, c) {
+		t.Errorf("Values received are %v, not %v", got, c)
+	}
+	ch := make(chan interface{}, 9)
+	for _, v := range pointers() {
+		ch <- v
+	}
+	close(ch)
+	i := 0
+	for v := range SortChannel(ch, FieldGetter("Id"), Descending) {
+		if id := v.(*Item).Id; id != int64(9-i) {
+			t.Errorf("Item %d has Id %d, not %d", i, id, 9-i)
+		}
+		i++
+	}
+	if i != 9 {
+		t.Errorf("Received %d items, not 9", i)
+	}
+}
+
+func TestSortModular(t *testing.T) {
+	angles := []float64{45, 90, 350, 180, 0, 270, 89.5, -20}
+	s := New(angles, nil, ModularAscending)
+	s.Modulus = 360
+	s.Origin = 90
+	s.Sort()
+	if c := []float64{90, 180, 270, -20, 350, 0, 45, 89.5}; !reflect.DeepEqual(angles, c) {
+		t.Errorf("Angles are %v, not %v", angles, c)
+	}
+	hours := []int{23, 1, 6, 12, 5}
+	s = New(hours, nil, ModularDescending)
+	s.Modulus = 24
+	s.Origin = 6
+	s.Sort()
+	if c := []int{5, 1, 23, 12, 6}; !reflect.DeepEqual(hours, c) {
+		t.Errorf("Hours are %v, not %v", hours, c)
+	}
+	defer func() {
+		if x := recover(); x == nil {
+			t.Error("Sorting without a modulus didn't cause a panic")
+		}
+	}()
+	Sort(hours, nil, ModularAscending)
+}
+
+func TestSortGetterNoValues(t *testing.T) {
+	none := func(reflect.Value) []reflect.Value { return nil }
+	if err := Validate(items(), none, Ascending); err == nil || err.Error() != errNoValues {
+		t.Errorf("Validate returned %v for a getter returning no values", err)
+	}
+	// Not an error for an empty slice
+	Sort([]Item{}, none, Ascending)
+	defer func() {
+		if x := recover(); x != errNoValues {
+			t.Errorf("Sort panicked with %v, not %q", x, errNoValues)
+		}
+	}()
+	Sort(items(), none, Ascending)
+}
+
+type Host struct {
+	Name       string
+	Attributes map[string]string
+}
+
+func hosts() []Host {
+	return []Host{
+		{"a", map[string]string{"priority": "2", "zone": "x"}},
+		{"b", map[string]string{}},
+		{"c", map[string]string{"priority": "1", "zone": "y", "rack": "3"}},
+		{"d", nil},
+		{"e", map[string]string{"priority": "3"}},
+	}
+}
+
+func hostNames(hs []Host) string {
+	var b strings.Builder
+	for _, h := range hs {
+		b.WriteString(h.Name)
+	}
+	return b.String()
+}
+
+func TestMapFieldGetter(t *testing.T) {
+	hs := hosts()
+	s := New(hs, MapFieldGetter("Attributes", "priority"), Ascending)
+	s.Policy.Nils = Last
+	s.Sort()
+	if n := hostNames(hs); n[:3] != "cae" {
+		t.Errorf("Hosts sorted by priority are %s", n)
+	}
+	Sort(hs, MapLenGetter("Attributes"), Descending)
+	if n := hostNames(hs); n[:3] != "cae" {
+		t.Errorf("Hosts sorted by number of attributes are %s", n)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("No panic when sorting by a field that isn't a map")
+		}
+	}()
+	Sort(hs, MapLenGetter("Name"), Ascending)
+}
+
+func TestSorterClone(t *testing.T) {
+	s := New(nil, FieldGetter("Id"), Descending)
+	s.Policy.Nils = Last
+	c := s.Clone()
+	if c.Ordering != Descending || c.Policy != s.Policy || c.Getter == nil {
+		t.Errorf("Clone has a different configuration: %+v", c)
+	}
+	var wg sync.WaitGroup
+	slices := make([][]Item, 8)
+	for i := range slices {
+		slices[i] = items()
+		wg.Add(1)
+		go func(is []Item) {
+			defer wg.Done()
+			c := s.Clone()
+			c.Slice = reflect.ValueOf(is)
+			c.Sort()
+		}(slices[i])
+	}
+	wg.Wait()
+	for i, is := range slices {
+		for j := 1; j < len(is); j++ {
+			if is[j-1].Id < is[j].Id {
+				t.Errorf("slices[%d] is not sorted: %v", i, is)
+				break
+			}
+		}
+	}
+}
+
+func TestSortedCopy(t *testing.T) {
 	is := items()
-	AscByField(is, "Id")
-	for i, v := range is {
+	sorted, ok := SortedCopy(is, FieldGetter("Id"), Ascending).([]Item)
+	if !ok {
+		t.Fatal("Sorted copy of an []Item isn't an []Item")
+	}
+	if !reflect.DeepEqual(is, items()) {
+		t.Error("SortedCopy modified the slice")
+	}
+	for i, v := range sorted {
 		if v.Id != int64(i+1) {
-			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+			t.Errorf("sorted[%d].Id is not %d, but %d", i, i+1, v.Id)
 		}
 	}
+	a := [3]int{3, 1, 2}
+	if c := SortedCopy(&a, nil, Descending).([]int); !reflect.DeepEqual(c, []int{3, 2, 1}) || a != [3]int{3, 1, 2} {
+		t.Errorf("Sorted copy of %v is %v", a, c)
+	}
 }
 
-func TestDescByFieldInt64(t *testing.T) {
-	is := items()
-	DescByField(is, "Id")
-	l := len(is)
-	for i, v := range is {
-		if v.Id != int64(l-i) {
-			t.Errorf("is[%d].Id is not %d, but %d", i, l-i, v.Id)
+func TestSorterFlip(t *testing.T) {
+	flips := map[Ordering]Ordering{
+		Ascending:                 Descending,
+		Descending:                Ascending,
+		CaseInsensitiveAscending:  CaseInsensitiveDescending,
+		CaseInsensitiveDescending: CaseInsensitiveAscending,
+		DescendingStableTies:      Ascending,
+		NonZeroFirstAscending:     NonZeroFirstDescending,
+		NonZeroFirstDescending:    NonZeroFirstAscending,
+		ModularAscending:          ModularDescending,
+		ModularDescending:         ModularAscending,
+	}
+	for o, c := range flips {
+		s := &Sorter{Ordering: o}
+		if s.Flip(); s.Ordering != c {
+			t.Errorf("%v flipped to %v, not %v", o, s.Ordering, c)
+		}
+	}
+	for _, o := range []Ordering{Ascending, CaseInsensitiveDescending} {
+		is := items()
+		s := New(is, FieldGetter("Name"), o)
+		s.Flip()
+		s.Sort()
+		c := items()
+		New(c, FieldGetter("Name"), flips[o]).Sort()
+		for i := range is {
+			if !strings.EqualFold(is[i].Name, c[i].Name) {
+				t.Errorf("%v flipped: is[%d].Name is not %s, but %s", o, i, c[i].Name, is[i].Name)
+			}
 		}
 	}
 }
 
-func TestAscByIndex(t *testing.T) {
-	is := nestedIntSlice()
-	AscByIndex(is, 2)
-	if !sort.IntsAreSorted([]int{is[0][2], is[1][2], is[2][2], is[3][2]}) {
-		t.Errorf("Nested int slice is not sorted by index 2 in child slices: %v", is)
+func TestSemverGetter(t *testing.T) {
+	vs := []string{"1.10.0", "1.0.0", "v1.2.0", "1.0.0-rc1", "1.0.0-alpha.10", "bogus", "1.0.0-alpha.2", "1.0.0-alpha", "1.0.0-alpha.beta", "0.9.9+build.5"}
+	Sort(vs, SemverGetter(nil), Ascending)
+	c := []string{"bogus", "0.9.9+build.5", "1.0.0-alpha", "1.0.0-alpha.2", "1.0.0-alpha.10", "1.0.0-alpha.beta", "1.0.0-rc1", "1.0.0", "v1.2.0", "1.10.0"}
+	if !reflect.DeepEqual(vs, c) {
+		t.Errorf("Versions are %v, not %v", vs, c)
+	}
+	Sort(vs, SemverGetter(nil), Descending)
+	if vs[0] != "1.10.0" || vs[len(vs)-2] != "0.9.9+build.5" || vs[len(vs)-1] != "bogus" {
+		t.Errorf("Versions in descending order are %v", vs)
 	}
+	defer func() {
+		if recover() == nil {
+			t.Error("No panic for an invalid version with an AsError policy")
+		}
+	}()
+	s := New(vs, SemverGetter(nil), Ascending)
+	s.Policy.Nils = AsError
+	s.Sort()
 }
 
-// func TestAscIntArray(t *testing.T) {
-// 	ints := [...]int{4, 3, 1, 5, 2}
-// 	Asc(ints)
-// 	if !sort.IntsAreSorted(ints[:]) {
-// 		t.Errorf("Array ints weren't sorted: %v", ints)
-// 	}
-// }
-
-func TestAscByFieldTime(t *testing.T) {
-	is := items()
-	AscByField(is, "Date")
-	c := dates()
-	for i, v := range is {
-		if !v.Date.Equal(c[i]) {
-			t.Errorf("is[%d].Date is not %v, but %v", i, c[i], v.Date)
+func TestByZoneThenTime(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	ts := []time.Time{
+		time.Date(2020, 1, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2020, 1, 1, 9, 0, 0, 0, tokyo),
+		time.Date(2020, 1, 1, 9, 0, 0, 0, ny),
+		time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2019, 12, 31, 9, 0, 0, 0, ny),
+		time.Date(2020, 1, 1, 8, 0, 0, 0, tokyo),
+	}
+	c := []time.Time{ts[4], ts[2], ts[5], ts[1], ts[3], ts[0]}
+	ByZoneThenTime(ts, nil, Ascending)
+	for i, v := range ts {
+		if v.Location() != c[i].Location() || !v.Equal(c[i]) {
+			t.Errorf("ts[%d] is not %v, but %v", i, c[i], v)
+		}
+	}
+	is := []Item{{Id: 1, Date: ts[5]}, {Id: 2, Date: ts[2]}, {Id: 3, Date: ts[3]}}
+	ByZoneThenTime(is, FieldGetter("Date"), Descending)
+	for i, id := range []int64{3, 2, 1} {
+		if is[i].Id != id {
+			t.Errorf("is[%d].Id is not %d, but %d", i, id, is[i].Id)
 		}
 	}
 }
 
-func TestDescByFieldTime(t *testing.T) {
+func TestAscByPredicate(t *testing.T) {
+	large := func(v reflect.Value) bool {
+		return v.FieldByName("Id").Int() > 5
+	}
 	is := items()
-	DescByField(is, "Date")
-	c := dates()
-	l := len(is)
+	AscByPredicate(is, large)
 	for i, v := range is {
-		if !v.Date.Equal(c[l-i-1]) {
-			t.Errorf("is[%d].Date is not %v, but %v", i, c[l-i], v.Date)
+		if (i >= 5) != (v.Id > 5) {
+			t.Errorf("is[%d].Id is %d", i, v.Id)
 		}
 	}
-}
-
-type TestStruct struct {
-	TimePtr    *time.Time
-	Invalid    InvalidType
-	unexported int
-}
-
-type InvalidType struct {
-	Foo string
-	Bar int
-}
-
-func testStructs() []TestStruct {
-	return []TestStruct{
-		{
-			TimePtr:    &now,
-			Invalid:    InvalidType{"foo", 123},
-			unexported: 5,
-		},
-		{
-			TimePtr:    &now,
-			Invalid:    InvalidType{"bar", 456},
-			unexported: 6,
-		},
+	DescByPredicate(is, large)
+	for i, v := range is {
+		if (i < 4) != (v.Id > 5) {
+			t.Errorf("is[%d].Id is %d", i, v.Id)
+		}
 	}
 }
 
-func testPointers() []*TestStruct {
-	return []*TestStruct{
-		{
-			TimePtr:    &now,
-			Invalid:    InvalidType{"foo", 123},
-			unexported: 5,
-		},
-		{
-			TimePtr:    &now,
-			Invalid:    InvalidType{"bar", 456},
-			unexported: 6,
-		},
+func TestDescByFieldNullsLast(t *testing.T) {
+	rs := records()
+	DescByFieldNullsLast(rs, "Score")
+	ids := recordIds(rs)
+	if c := []int{1, 5, 3}; !reflect.DeepEqual(ids[:3], c) {
+		t.Errorf("Records with scores are %v, not %v", ids[:3], c)
 	}
-}
-
-func TestAscByFieldInvalidType(t *testing.T) {
-	// Sorting by an invalid type should cause a panic
-	defer func() {
-		if x := recover(); x == nil {
-			t.Fatal("Sorting by an unrecognized type didn't cause a panic")
-		}
-	}()
-	is := testStructs()
-	AscByField(is, "Invalid")
-}
-
-func TestAscByFieldUnexportedType(t *testing.T) {
-	// Sorting an unexported type should cause a panic
-	// TODO: This should test on a field outside the package
-	return // TEMP
-	defer func() {
-		if x := recover(); x == nil {
-			t.Fatal("Sorting an unexported type didn't cause a panic")
+	for _, r := range rs[3:] {
+		if r.Score != nil {
+			t.Errorf("Record %d with a score is placed after the nils", r.Id)
 		}
-	}()
-	is := testStructs()
-	AscByField(is, "unexported")
-}
-
-func TestAscByFieldPointer(t *testing.T) {
-	// Sorting by a pointer type shouldn't cause a panic
-	is := testStructs()
-	AscByField(is, "TimePtr")
+	}
+	AscByFieldNullsLast(rs, "Score")
+	if ids := recordIds(rs); ids[0] != 3 || rs[3].Score != nil || rs[4].Score != nil {
+		t.Errorf("Records sorted ascending with nils last: %v", ids)
+	}
 }
 
-func TestPointerSliceAscByFieldInt64(t *testing.T) {
-	// Sorting a slice of pointers shouldn't cause a panic
-	is := pointers()
-	AscByField(is, "Id")
+type Row struct {
+	Id    int
+	Count sql.NullInt64
 }
 
-func TestPointerSliceAscByFieldPointer(t *testing.T) {
-	// Sorting a slice of pointers by a pointer type shouldn't cause a panic
-	is := testPointers()
-	AscByField(is, "TimePtr")
+func TestDescByFieldNullsLastSQL(t *testing.T) {
+	rows := []Row{
+		{1, sql.NullInt64{Int64: 5, Valid: true}},
+		{2, sql.NullInt64{}},
+		{3, sql.NullInt64{Int64: 10, Valid: true}},
+		{4, sql.NullInt64{Int64: 0, Valid: true}},
+	}
+	DescByFieldNullsLast(rows, "Count")
+	c := []int{3, 1, 4, 2}
+	for i, v := range rows {
+		if v.Id != c[i] {
+			t.Errorf("rows[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
 }
 
 func TestAscEmptySlice(t *testing.T) {
@@ -371,6 +3685,15 @@ func benchmarkInt64s(n int) []int64 {
 	return ints
 }
 
+func benchmarkStrings(l int) []string {
+	strs := make([]string, l)
+	n := names()
+	for i := range strs {
+		strs[i] = n[i%len(n)]
+	}
+	return strs
+}
+
 func benchmarkItems(l int) []Item {
 	is := make([]Item, l, l)
 	n := names()
@@ -474,6 +3797,128 @@ func BenchmarkAscIntsAfterSetup(b *testing.B) {
 	New(is, previousGetter(vals), Ascending).Sort()
 }
 
+func BenchmarkSortInts(b *testing.B) {
+	b.StopTimer()
+	ints := benchmarkInts(b.N)
+	b.StartTimer()
+	SortInts(ints, Ascending)
+}
+
+func BenchmarkSortStrings(b *testing.B) {
+	b.StopTimer()
+	strs := benchmarkStrings(b.N)
+	b.StartTimer()
+	SortStrings(strs, Ascending)
+}
+
+func BenchmarkAscStrings(b *testing.B) {
+	b.StopTimer()
+	strs := benchmarkStrings(b.N)
+	b.StartTimer()
+	Asc(strs)
+}
+
+func BenchmarkSortStringsInsensitive(b *testing.B) {
+	b.StopTimer()
+	strs := benchmarkStrings(b.N)
+	b.StartTimer()
+	SortStrings(strs, CaseInsensitiveAscending)
+}
+
+func BenchmarkCiAscStrings(b *testing.B) {
+	b.StopTimer()
+	strs := benchmarkStrings(b.N)
+	b.StartTimer()
+	CiAsc(strs)
+}
+
+func BenchmarkReverseInts(b *testing.B) {
+	ints := benchmarkInts(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ReverseInts(ints)
+	}
+}
+
+func BenchmarkReverseIntsReflect(b *testing.B) {
+	ints := benchmarkInts(1000)
+	s := reverser{New(ints, nil, 0)}
+	s.itemType = reflect.TypeOf(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ReverseInterface(s)
+	}
+}
+
+func BenchmarkCiAscByField(b *testing.B) {
+	b.StopTimer()
+	is := benchmarkItems(b.N)
+	b.StartTimer()
+	CiAscByField(is, "Name")
+}
+
+func BenchmarkCiDescByField(b *testing.B) {
+	b.StopTimer()
+	is := benchmarkItems(b.N)
+	b.StartTimer()
+	CiDescByField(is, "Name")
+}
+
+func BenchmarkSortByLess(b *testing.B) {
+	b.StopTimer()
+	is := benchmarkItems(b.N)
+	key := FieldKey("Date")
+	less := func(a, b reflect.Value) bool {
+		return a.Interface().(time.Time).Before(b.Interface().(time.Time))
+	}
+	b.StartTimer()
+	SortByLess(is, key, less)
+}
+
+func BenchmarkSliceByFieldLess(b *testing.B) {
+	// The same as the above, but retrieving the field in each comparison
+	b.StopTimer()
+	is := benchmarkItems(b.N)
+	key := FieldKey("Date")
+	v := reflect.ValueOf(is)
+	b.StartTimer()
+	sort.Slice(is, func(i, j int) bool {
+		return key(v.Index(i)).Interface().(time.Time).Before(key(v.Index(j)).Interface().(time.Time))
+	})
+}
+
+func BenchmarkAscByFieldShort(b *testing.B) {
+	is := benchmarkItems(8)
+	for i := range is {
+		is[i].Id = int64((i * 5) % 8)
+	}
+	tmp := make([]Item, len(is))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(tmp, is)
+		AscByField(tmp, "Id")
+	}
+}
+
+func BenchmarkAscByFieldShortNoInsertionSort(b *testing.B) {
+	defer func(n int) {
+		insertionSortThreshold = n
+	}(insertionSortThreshold)
+	insertionSortThreshold = 0
+	BenchmarkAscByFieldShort(b)
+}
+
+func BenchmarkAscByFieldCollated(b *testing.B) {
+	is := benchmarkItems(8)
+	tmp := make([]Item, len(is))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(tmp, is)
+		AscByFieldCollated(tmp, "Name", swedish)
+	}
+}
+
 func BenchmarkDescInts(b *testing.B) {
 	b.StopTimer()
 	ints := benchmarkInts(b.N)
@@ -570,3 +4015,130 @@ func BenchmarkAscPointersByTimeAfterSetup(b *testing.B) {
 	b.StartTimer()
 	New(is, previousGetter(vals), Ascending).Sort()
 }
+
+func BenchmarkAscByFieldRepeated(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		AscByField(items(), "Id")
+	}
+}
+
+func BenchmarkAscByResolvedFieldRepeated(b *testing.B) {
+	index, _ := ResolveField(reflect.TypeOf(Item{}), "Id")
+	g := FieldByIndexGetter(index)
+	for i := 0; i < b.N; i++ {
+		Sort(items(), g, Ascending)
+	}
+}
+
+func benchmarkFloats(n int) []float64 {
+	fs := make([]float64, n)
+	for i := range fs {
+		fs[i] = float64((i * 7919) % n)
+	}
+	return fs
+}
+
+func BenchmarkAscFloats(b *testing.B) {
+	b.ReportAllocs()
+	orig := benchmarkFloats(1000)
+	fs := make([]float64, len(orig))
+	for i := 0; i < b.N; i++ {
+		copy(fs, orig)
+		Sort(fs, nil, Ascending)
+	}
+}
+
+func BenchmarkSortFloatsReused(b *testing.B) {
+	b.ReportAllocs()
+	orig := benchmarkFloats(1000)
+	fs := make([]float64, len(orig))
+	var buf []float64
+	for i := 0; i < b.N; i++ {
+		copy(fs, orig)
+		buf = SortFloats(buf, &fs, nil, Ascending)
+	}
+}
+
+func BenchmarkAscReverseSortedInts(b *testing.B) {
+	b.StopTimer()
+	ints := make([]int, b.N)
+	for i := range ints {
+		ints[i] = b.N - i
+	}
+	b.StartTimer()
+	Asc(ints)
+}
+
+// Returns n pointers sorted by Id, except for every 50th one, which is
+// moved a few places ahead.
+func nearlySortedPointers(n int) []*Item {
+	is := make([]*Item, n)
+	for i := range is {
+		is[i] = &Item{Id: int64(i)}
+	}
+	for i := 0; i+5 < n; i += 50 {
+		is[i].Id += 5
+	}
+	return is
+}
+
+// Returns the number of elements permute writes to apply order, i.e. the
+// number of elements that move.
+func moved(order []int) int {
+	n := 0
+	for i, v := range order {
+		if v != i {
+			n++
+		}
+	}
+	return n
+}
+
+func benchmarkNearlySorted(b *testing.B, sortFunc func(sort.Interface)) {
+	is := nearlySortedPointers(10000)
+	tmp := make([]*Item, len(is))
+	writes := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(tmp, is)
+		s := New(tmp, FieldGetter("Id"), Ascending)
+		s.setup()
+		sortFunc(s.lesser())
+		writes += moved(s.order())
+		s.permute()
+	}
+	b.ReportMetric(float64(writes)/float64(b.N), "writes/op")
+}
+
+func BenchmarkAdaptiveSortNearlySortedPointers(b *testing.B) {
+	benchmarkNearlySorted(b, adaptiveSort)
+}
+
+func BenchmarkSortNearlySortedPointers(b *testing.B) {
+	benchmarkNearlySorted(b, sort.Sort)
+}
+
+func BenchmarkMaxByField(b *testing.B) {
+	b.ReportAllocs()
+	is := benchmarkItems(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MaxByField(is, "Id")
+	}
+}
+
+func BenchmarkMaxByFieldGetter(b *testing.B) {
+	// Find the max after retrieving all the fields up front
+	b.ReportAllocs()
+	is := benchmarkItems(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vals := FieldGetter("Id")(reflect.ValueOf(is))
+		max := 0
+		for j, v := range vals {
+			if v.Int() > vals[max].Int() {
+				max = j
+			}
+		}
+	}
+}
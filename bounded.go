@@ -0,0 +1,125 @@
+package sortutil
+
+import (
+	"container/heap"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// A BoundedSorter keeps the first (at most) K of the elements pushed to it,
+// according to the values retrieved by Getter in the given Ordering. E.g.
+// with Descending, it keeps the K largest elements. Unlike sorting a slice
+// and keeping the first K elements, the elements don't have to be known up
+// front, and only K of them are kept in memory.
+type BoundedSorter struct {
+	K        int
+	Getter   Getter
+	Ordering Ordering
+	elems    []reflect.Value
+	keys     []reflect.Value
+	less     func(a, b reflect.Value) bool
+}
+
+// Returns a BoundedSorter which keeps the first k elements pushed to it
+// according to the items retrieved by getter, in the given ordering. getter
+// may be nil if the elements are of a basic type.
+func NewBounded(k int, getter Getter, ordering Ordering) *BoundedSorter {
+	return &BoundedSorter{
+		K:        k,
+		Getter:   getter,
+		Ordering: ordering,
+	}
+}
+
+// Push an element, evicting the element that sorts last if more than K
+// elements would be kept. All the elements pushed must be of the same type.
+// A runtime panic will occur if elem is nil, since it has no type.
+func (b *BoundedSorter) Push(elem interface{}) {
+	if elem == nil {
+		panic(fmt.Sprintf("Cannot push nil to a BoundedSorter with %d elements; elements must have a type", len(b.elems)))
+	}
+	if b.K < 1 {
+		return
+	}
+	if b.Getter == nil {
+		b.Getter = SimpleGetter()
+	}
+	v := reflect.ValueOf(elem)
+	one := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+	one.Index(0).Set(v)
+	key := b.Getter(one)[0]
+	if b.less == nil {
+		b.less = (&Sorter{Getter: b.Getter, Ordering: b.Ordering}).lessFunc([]reflect.Value{key})
+	}
+	b.push(one.Index(0), key)
+}
+
+func (b *BoundedSorter) push(elem, key reflect.Value) {
+	if len(b.elems) < b.K {
+		heap.Push(worst{b}, [2]reflect.Value{elem, key})
+		return
+	}
+	if b.less(key, b.keys[0]) {
+		b.elems[0], b.keys[0] = elem, key
+		heap.Fix(worst{b}, 0)
+	}
+}
+
+// Returns the number of elements currently kept.
+func (b *BoundedSorter) Len() int {
+	return len(b.elems)
+}
+
+// Returns a new slice, e.g. an []int if ints were pushed, with the kept
+// elements in sorted order, or nil if no elements have been pushed.
+func (b *BoundedSorter) Slice() interface{} {
+	if len(b.elems) == 0 {
+		return nil
+	}
+	return b.sorted(reflect.SliceOf(b.elems[0].Type())).Interface()
+}
+
+// Returns a new slice of type t with the kept elements in sorted order.
+func (b *BoundedSorter) sorted(t reflect.Type) reflect.Value {
+	sorted := worst{&BoundedSorter{
+		elems: append([]reflect.Value(nil), b.elems...),
+		keys:  append([]reflect.Value(nil), b.keys...),
+		less:  b.less,
+	}}
+	sort.Sort(sort.Reverse(sorted))
+	s := reflect.MakeSlice(t, len(sorted.elems), len(sorted.elems))
+	for i, v := range sorted.elems {
+		s.Index(i).Set(v)
+	}
+	return s
+}
+
+// A heap.Interface with the element that sorts last at the root.
+type worst struct{ *BoundedSorter }
+
+func (w worst) Len() int {
+	return len(w.elems)
+}
+
+func (w worst) Less(i, j int) bool {
+	return w.less(w.keys[j], w.keys[i])
+}
+
+func (w worst) Swap(i, j int) {
+	w.elems[i], w.elems[j] = w.elems[j], w.elems[i]
+	w.keys[i], w.keys[j] = w.keys[j], w.keys[i]
+}
+
+func (w worst) Push(x interface{}) {
+	v := x.([2]reflect.Value)
+	w.elems = append(w.elems, v[0])
+	w.keys = append(w.keys, v[1])
+}
+
+func (w worst) Pop() interface{} {
+	l := len(w.elems) - 1
+	v := [2]reflect.Value{w.elems[l], w.keys[l]}
+	w.elems, w.keys = w.elems[:l], w.keys[:l]
+	return v
+}
@@ -0,0 +1,84 @@
+package sortutil
+
+// A Builder builds up a multi-key sort step by step, e.g.
+//
+//	sortutil.Build(people).ByField("Last").CaseInsensitive().ByField("First").Do()
+//
+// Ordering methods apply to the key that was added last. If they're called
+// before any keys have been added, a key for the elements themselves is
+// added, e.g. Build(ints).Descending().Do() sorts ints in descending order.
+type Builder struct {
+	slice interface{}
+	keys  []KeySpec
+}
+
+// Returns a Builder for sorting slice.
+func Build(slice interface{}) *Builder {
+	return &Builder{slice: slice}
+}
+
+// Add a key retrieved using getter, in ascending order.
+func (b *Builder) By(getter Getter) *Builder {
+	b.keys = append(b.keys, KeySpec{Getter: getter})
+	return b
+}
+
+// Add a key for the field with name, in ascending order.
+func (b *Builder) ByField(name string) *Builder {
+	return b.By(FieldGetter(name))
+}
+
+// Add a key for the element with index in child slices, in ascending order.
+func (b *Builder) ByIndex(index int) *Builder {
+	return b.By(IndexGetter(index))
+}
+
+// Sort the last key in descending order.
+func (b *Builder) Descending() *Builder {
+	k := b.last()
+	switch k.Ordering {
+	case Ascending:
+		k.Ordering = Descending
+	case CaseInsensitiveAscending:
+		k.Ordering = CaseInsensitiveDescending
+	}
+	return b
+}
+
+// Compare the last key, which must be a string, []byte or []rune key,
+// case-insensitively.
+func (b *Builder) CaseInsensitive() *Builder {
+	k := b.last()
+	switch k.Ordering {
+	case Ascending:
+		k.Ordering = CaseInsensitiveAscending
+	case Descending:
+		k.Ordering = CaseInsensitiveDescending
+	}
+	return b
+}
+
+// Place the nil, empty and NaN values of the last key according to p.
+func (b *Builder) WithPolicy(p Policy) *Builder {
+	b.last().Policy = p
+	return b
+}
+
+// Returns the key that was added last, adding one for the elements
+// themselves if there are none.
+func (b *Builder) last() *KeySpec {
+	if len(b.keys) == 0 {
+		b.By(nil)
+	}
+	return &b.keys[len(b.keys)-1]
+}
+
+// Returns the keys that have been added.
+func (b *Builder) Keys() []KeySpec {
+	return b.keys
+}
+
+// Sort the slice by the keys that have been added. See MultiSort.
+func (b *Builder) Do() {
+	MultiSort(b.slice, b.keys...)
+}
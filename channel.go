@@ -0,0 +1,25 @@
+package sortutil
+
+// Returns a channel on which the values received from in are sent in sorted
+// order, using a Getter in the order specified by Ordering, e.g. to sort the
+// output of a pipeline stage. Since a value can't be known to come first
+// until all the values have been seen, the values are buffered until in is
+// closed, and are only then sorted and sent. The returned channel is closed
+// after the last value has been sent. getter may be nil to sort by the values
+// themselves. A runtime panic will occur in the sending goroutine if the
+// values can't be sorted.
+func SortChannel(in <-chan interface{}, getter Getter, ordering Ordering) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		var buf []interface{}
+		for v := range in {
+			buf = append(buf, v)
+		}
+		New(buf, getter, ordering).Sort()
+		for _, v := range buf {
+			out <- v
+		}
+	}()
+	return out
+}
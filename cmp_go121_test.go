@@ -0,0 +1,49 @@
+//go:build go1.21
+
+package sortutil
+
+import (
+	"cmp"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func cmpFloats() []float64 {
+	return []float64{3, math.NaN(), math.Inf(-1), 0, math.NaN(), -2.5, math.Inf(1), 1}
+}
+
+// Compares floats like reflect.DeepEqual, but with NaNs equal to each other.
+func sameFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] && !(math.IsNaN(a[i]) && math.IsNaN(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFloatsMatchCmpCompare(t *testing.T) {
+	fs := cmpFloats()
+	Asc(fs)
+	c := cmpFloats()
+	slices.SortFunc(c, cmp.Compare[float64])
+	if !sameFloats(fs, c) {
+		t.Errorf("Ascending floats are %v, not %v like with cmp.Compare", fs, c)
+	}
+	Desc(fs)
+	slices.SortFunc(c, func(a, b float64) int { return cmp.Compare(b, a) })
+	if !sameFloats(fs, c) {
+		t.Errorf("Descending floats are %v, not %v like with cmp.Compare", fs, c)
+	}
+	ms := []Measurement{{1, 2}, {2, math.NaN()}, {3, -1}}
+	AscByField(ms, "Value")
+	ids := []int{ms[0].Id, ms[1].Id, ms[2].Id}
+	if !reflect.DeepEqual(ids, []int{2, 3, 1}) {
+		t.Errorf("Measurements sorted by Value are %v, not [2 3 1]", ids)
+	}
+}
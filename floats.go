@@ -0,0 +1,161 @@
+package sortutil
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Sort a slice by float values retrieved using getter, in the given ordering,
+// using dst as scratch space for the values. The (possibly grown) scratch
+// slice is returned so it can be passed to the next call, avoiding the
+// allocation of a new set of values each time when sorting repeatedly, e.g.
+// in a loop. getter may be nil if sorting a []float64 or []float32. slice
+// may be a pointer to a slice. Sorting a []float64 or []float32, or a pointer
+// to one, with a nil getter and a dst with enough capacity doesn't allocate
+// (though passing a slice rather than a pointer to it as an interface{} may
+// allocate in the caller). A runtime panic will occur if the values aren't
+// floats, or if ordering is case-insensitive.
+func SortFloats(dst []float64, slice interface{}, getter Getter, ordering Ordering) []float64 {
+	if ordering != Ascending && ordering != Descending {
+		panic(fmt.Sprintf("Invalid ordering %v for floats", ordering))
+	}
+	p := floatPairs{descending: ordering == Descending}
+	if getter == nil {
+		switch fs := slice.(type) {
+		case []float64:
+			p.floats = fs
+		case *[]float64:
+			if fs == nil {
+				return dst[:0]
+			}
+			p.floats = *fs
+		case []float32:
+			p.floats32 = fs
+		case *[]float32:
+			if fs == nil {
+				return dst[:0]
+			}
+			p.floats32 = *fs
+		}
+	}
+	dst = dst[:0]
+	switch {
+	case p.floats != nil:
+		dst = append(dst, p.floats...)
+	case p.floats32 != nil:
+		for _, f := range p.floats32 {
+			dst = append(dst, float64(f))
+		}
+	default:
+		sv := reflect.ValueOf(slice)
+		if sv.Kind() == reflect.Ptr {
+			if sv.IsNil() {
+				return dst
+			}
+			sv = sv.Elem()
+		}
+		if sv.Len() < 2 {
+			return dst
+		}
+		if getter == nil {
+			for i := 0; i < sv.Len(); i++ {
+				dst = appendFloat(dst, indirect(sv.Index(i)))
+			}
+		} else {
+			for _, v := range getter(sv) {
+				dst = appendFloat(dst, v)
+			}
+		}
+		p.swap = reflect.Swapper(sv.Interface())
+	}
+	p.keys = dst
+	quickSort(p, 0, len(dst))
+	return dst
+}
+
+func appendFloat(dst []float64, v reflect.Value) []float64 {
+	if k := v.Kind(); k != reflect.Float32 && k != reflect.Float64 {
+		panic(fmt.Sprintf("Cannot sort by type %v as floats", v.Type()))
+	}
+	return append(dst, v.Float())
+}
+
+// Sorts the elements of a slice along with their float keys. The elements
+// are either floats or floats32, which are swapped directly, or are swapped
+// by swap.
+type floatPairs struct {
+	keys       []float64
+	floats     []float64
+	floats32   []float32
+	swap       func(i, j int)
+	descending bool
+}
+
+func (p floatPairs) Less(i, j int) bool {
+	a, b := p.keys[i], p.keys[j]
+	if p.descending {
+		return a > b || !math.IsNaN(a) && math.IsNaN(b)
+	}
+	return a < b || math.IsNaN(a) && !math.IsNaN(b)
+}
+
+func (p floatPairs) Swap(i, j int) {
+	p.keys[i], p.keys[j] = p.keys[j], p.keys[i]
+	switch {
+	case p.floats != nil:
+		p.floats[i], p.floats[j] = p.floats[j], p.floats[i]
+	case p.floats32 != nil:
+		p.floats32[i], p.floats32[j] = p.floats32[j], p.floats32[i]
+	default:
+		p.swap(i, j)
+	}
+}
+
+// A simple in-place quicksort of [lo, hi) which, unlike sort.Sort, doesn't
+// require the value being sorted to be converted to an interface, and so
+// doesn't cause it to escape to the heap.
+func quickSort(p floatPairs, lo, hi int) {
+	for hi-lo > 12 {
+		m := lo + (hi-lo)/2
+		// Median of three
+		if p.Less(m, lo) {
+			p.Swap(m, lo)
+		}
+		if p.Less(hi-1, lo) {
+			p.Swap(hi-1, lo)
+		}
+		if p.Less(hi-1, m) {
+			p.Swap(hi-1, m)
+		}
+		p.Swap(m, lo)
+		i, j := lo+1, hi-1
+		for {
+			for i < hi && p.Less(i, lo) {
+				i++
+			}
+			for j > lo && p.Less(lo, j) {
+				j--
+			}
+			if i >= j {
+				break
+			}
+			p.Swap(i, j)
+			i++
+			j--
+		}
+		p.Swap(lo, j)
+		if j-lo < hi-j-1 {
+			quickSort(p, lo, j)
+			lo = j + 1
+		} else {
+			quickSort(p, j+1, hi)
+			hi = j
+		}
+	}
+	for i := lo + 1; i < hi; i++ {
+		for j := i; j > lo && p.Less(j, j-1); j-- {
+			p.Swap(j, j-1)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package sortutil
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sort a slice by how often each of the values retrieved by getter occurs in
+// it. With Descending, the elements with the most common values come first;
+// with Ascending, the elements with the least common values come first.
+// Values that occur equally often are sorted in ascending order, so elements
+// with equal values are grouped together, and keep their original relative
+// order. A runtime panic will occur if ordering is case-insensitive.
+func SortByFrequency(slice interface{}, getter Getter, ordering Ordering) {
+	if ordering != Ascending && ordering != Descending {
+		panic(fmt.Sprintf("Invalid ordering %v for frequencies", ordering))
+	}
+	s := New(slice, getter, Ascending)
+	if s.Slice.Len() < 2 {
+		return
+	}
+	s.setup()
+	if len(s.vals) > 1 {
+		sort.Stable(frequency{
+			Interface:  s.lesser(),
+			Sorter:     s,
+			counts:     countValues(s.vals),
+			descending: ordering == Descending,
+		})
+	}
+	s.permute()
+}
+
+type frequency struct {
+	sort.Interface
+	*Sorter
+	counts     []int // Number of occurrences of each of Sorter.vals
+	descending bool
+}
+
+func (f frequency) Len() int {
+	return f.Sorter.Len()
+}
+
+func (f frequency) Less(i, j int) bool {
+	a, b := f.counts[i], f.counts[j]
+	if a != b {
+		if f.descending {
+			return a > b
+		}
+		return a < b
+	}
+	return f.Interface.Less(i, j)
+}
+
+func (f frequency) Swap(i, j int) {
+	f.counts[i], f.counts[j] = f.counts[j], f.counts[i]
+	f.Sorter.Swap(i, j)
+}
+
+// Returns the number of times each value in vals occurs in vals.
+func countValues(vals []reflect.Value) []int {
+	keys := make([]interface{}, len(vals))
+	n := make(map[interface{}]int)
+	for i, v := range vals {
+		keys[i] = mapKey(v)
+		n[keys[i]]++
+	}
+	counts := make([]int, len(vals))
+	for i, k := range keys {
+		counts[i] = n[k]
+	}
+	return counts
+}
+
+// Returns a value usable as a map key which is equal for two values if they
+// would be considered equal when sorting.
+func mapKey(v reflect.Value) interface{} {
+	switch {
+	case isNil(v):
+		return nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return string(v.Bytes())
+	case v.Kind() == reflect.Slice:
+		// Slices aren't hashable, so they are identified by their type and
+		// the keys of their elements.
+		var b strings.Builder
+		for i := 0; i < v.Len(); i++ {
+			fmt.Fprintf(&b, "%#v,", mapKey(indirect(v.Index(i))))
+		}
+		return sliceKey{v.Type(), b.String()}
+	case v.Type() == t_time:
+		t := v.Interface().(time.Time)
+		return [2]int64{t.Unix(), int64(t.Nanosecond())}
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		// NaN is never equal to itself, so it would get a new entry every
+		// time it's used as a map key.
+		if math.IsNaN(v.Float()) {
+			return nanKey{v.Type()}
+		}
+	case v.Kind() == reflect.Complex64 || v.Kind() == reflect.Complex128:
+		c := v.Complex()
+		if math.IsNaN(real(c)) || math.IsNaN(imag(c)) {
+			return complexKey{v.Type(), partKey(real(c)), partKey(imag(c))}
+		}
+	}
+	return v.Interface()
+}
+
+type nanKey struct {
+	t reflect.Type
+}
+
+type complexKey struct {
+	t      reflect.Type
+	re, im interface{}
+}
+
+func partKey(f float64) interface{} {
+	if math.IsNaN(f) {
+		return nanKey{}
+	}
+	return f
+}
+
+type sliceKey struct {
+	t     reflect.Type
+	elems string
+}
+
+// Returns the keys of a map of counts, e.g. a map[string]int of word counts,
+// sorted by descending count. Keys with equal counts are sorted in ascending
+// order, so the result is the same every time. A runtime panic will occur if
+// m isn't a map with integer values, or if its keys can't be sorted.
+func SortedKeysByCount(m interface{}) []interface{} {
+	mv := reflect.ValueOf(m)
+	if mv.Kind() != reflect.Map {
+		panic(fmt.Sprintf("Cannot sort the keys of %T by count; it isn't a map", m))
+	}
+	var count func(v reflect.Value) int
+	switch k := mv.Type().Elem().Kind(); {
+	case isInt(k):
+		count = func(v reflect.Value) int { return int(v.Int()) }
+	case isUint(k):
+		count = func(v reflect.Value) int { return int(v.Uint()) }
+	default:
+		panic(fmt.Sprintf("Cannot sort the keys of %T by count; its values aren't integers", m))
+	}
+	keys := reflect.MakeSlice(reflect.SliceOf(mv.Type().Key()), 0, mv.Len())
+	counts := make([]int, 0, mv.Len())
+	iter := mv.MapRange()
+	for iter.Next() {
+		keys = reflect.Append(keys, iter.Key())
+		counts = append(counts, count(iter.Value()))
+	}
+	res := make([]interface{}, keys.Len())
+	if len(res) == 0 {
+		return res
+	}
+	s := New(keys.Interface(), nil, Ascending)
+	s.setup()
+	sort.Sort(frequency{
+		Interface:  s.lesser(),
+		Sorter:     s,
+		counts:     counts,
+		descending: true,
+	})
+	s.permute()
+	for i := range res {
+		res[i] = keys.Index(i).Interface()
+	}
+	return res
+}
@@ -0,0 +1,78 @@
+//go:build go1.18
+
+package sortutil
+
+import (
+	"sort"
+)
+
+// Sort a slice by a list of keys, e.g. SortByKeys(items, byPriority, byId).
+// Each key function returns an int for an element of s. Elements are ordered
+// by the first key in ascending order, then by the second key if the first
+// keys are equal, and so on. Use a negated key to sort by it in descending
+// order.
+func SortByKeys[T any](s []T, keys ...func(T) int) {
+	sort.Slice(s, func(i, j int) bool {
+		for _, key := range keys {
+			a, b := key(s[i]), key(s[j])
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+}
+
+// Ordered is satisfied by the types which support the < operator.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~string
+}
+
+// Sort a slice in ascending order by the value key returns for each element.
+// Like with Ascending, NaNs are placed first.
+func SortSlice[T any, K Ordered](s []T, key func(T) K) {
+	sort.Slice(s, keyLess(s, key, false))
+}
+
+// Sort a slice in descending order by the value key returns for each
+// element. Like with Descending, NaNs are placed last.
+func SortSliceDesc[T any, K Ordered](s []T, key func(T) K) {
+	sort.Slice(s, keyLess(s, key, true))
+}
+
+// Sort a slice by the value key returns for each element, in descending
+// order if descending is true, and in ascending order otherwise. Elements
+// with equal keys keep their original order in both directions.
+func SortSliceStable[T any, K Ordered](s []T, key func(T) K, descending bool) {
+	sort.SliceStable(s, keyLess(s, key, descending))
+}
+
+// Sort a slice using a three-way comparison function, which returns a
+// negative number if a sorts before b, a positive number if a sorts after b,
+// and 0 if they are equal. This allows sorting by keys which don't support
+// the < operator, e.g. by a time.Time field using time.Time.Compare (in Go
+// 1.20 and later):
+//
+//	SortSliceFunc(items, func(a, b Item) int { return a.Date.Compare(b.Date) })
+func SortSliceFunc[T any](s []T, cmp func(a, b T) int) {
+	sort.Slice(s, func(i, j int) bool {
+		return cmp(s[i], s[j]) < 0
+	})
+}
+
+func keyLess[T any, K Ordered](s []T, key func(T) K, descending bool) func(i, j int) bool {
+	if descending {
+		return func(i, j int) bool {
+			a, b := key(s[i]), key(s[j])
+			// b != b if b is NaN
+			return a > b || a == a && b != b
+		}
+	}
+	return func(i, j int) bool {
+		a, b := key(s[i]), key(s[j])
+		return a < b || a != a && b == b
+	}
+}
@@ -0,0 +1,23 @@
+//go:build go1.20
+
+package sortutil
+
+import (
+	"testing"
+)
+
+func TestSortSliceFuncTime(t *testing.T) {
+	is := items()
+	SortSliceFunc(is, func(a, b Item) int { return a.Date.Compare(b.Date) })
+	for i := 1; i < len(is); i++ {
+		if is[i].Date.Before(is[i-1].Date) {
+			t.Errorf("is[%d].Date is before is[%d].Date", i, i-1)
+		}
+	}
+	SortSliceFunc(is, func(a, b Item) int { return b.Date.Compare(a.Date) })
+	for i := 1; i < len(is); i++ {
+		if is[i].Date.After(is[i-1].Date) {
+			t.Errorf("is[%d].Date is after is[%d].Date", i, i-1)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+//go:build go1.18
+
+package sortutil
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSortByKeys(t *testing.T) {
+	is := items()
+	valid := func(i Item) int {
+		if i.Valid {
+			return 0
+		}
+		return 1
+	}
+	id := func(i Item) int {
+		return int(i.Id)
+	}
+	SortByKeys(is, valid, id)
+	c := []int64{1, 4, 6, 7, 9, 2, 3, 5, 8}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func itemId(i Item) int64 {
+	return i.Id
+}
+
+func TestSortSlice(t *testing.T) {
+	is := items()
+	SortSlice(is, itemId)
+	for i, v := range is {
+		if v.Id != int64(i+1) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, i+1, v.Id)
+		}
+	}
+}
+
+func TestSortSliceDesc(t *testing.T) {
+	is := items()
+	SortSliceDesc(is, itemId)
+	l := len(is)
+	for i, v := range is {
+		if v.Id != int64(l-i) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, l-i, v.Id)
+		}
+	}
+}
+
+func TestSortSliceStable(t *testing.T) {
+	is := items()
+	// Group by whether the Id is odd, keeping the original order
+	SortSliceStable(is, func(i Item) int64 { return i.Id % 2 }, true)
+	c := []int64{1, 9, 3, 7, 5, 6, 2, 8, 4}
+	for i, v := range is {
+		if v.Id != c[i] {
+			t.Errorf("is[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestSortSliceNaN(t *testing.T) {
+	nan := math.NaN()
+	fs := []float64{2, nan, 1}
+	id := func(f float64) float64 { return f }
+	SortSlice(fs, id)
+	if !math.IsNaN(fs[0]) || !reflect.DeepEqual(fs[1:], []float64{1, 2}) {
+		t.Errorf("NaN was not placed first in ascending order: %v", fs)
+	}
+	SortSliceDesc(fs, id)
+	if !math.IsNaN(fs[2]) || !reflect.DeepEqual(fs[:2], []float64{2, 1}) {
+		t.Errorf("NaN was not placed last in descending order: %v", fs)
+	}
+}
+
+func TestSortSliceFunc(t *testing.T) {
+	is := items()
+	SortSliceFunc(is, func(a, b Item) int { return int(b.Id - a.Id) })
+	for i, v := range is {
+		if v.Id != int64(9-i) {
+			t.Errorf("is[%d].Id is not %d, but %d", i, 9-i, v.Id)
+		}
+	}
+}
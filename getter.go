@@ -1,13 +1,22 @@
 package sortutil
 
 import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // A Getter is a function which takes a reflect.Value for a slice, and returns a
 // a slice of reflect.Value, e.g. a slice with a reflect.Value for each of the
 // Name fields from a reflect.Value for a slice of a struct type. It is used by
-// the sort functions to identify the elements to sort by.
+// the sort functions to identify the elements to sort by. The Getters in
+// this package dereference pointers and unwrap interface values, so that e.g.
+// a struct field of type interface{} holding ints can be sorted by.
 type Getter func(reflect.Value) []reflect.Value
 
 func valueSlice(l int) []reflect.Value {
@@ -15,31 +24,77 @@ func valueSlice(l int) []reflect.Value {
 	return s
 }
 
-// Returns a Getter which returns the values from a reflect.Value for a
-// slice. This is the default Getter used if none is passed to Sort.
-func SimpleGetter() Getter {
+// Dereferences pointers and unwraps interfaces until reaching a concrete
+// value. Like reflect.Indirect, returns the zero Value for a nil pointer. A
+// nil interface is returned as is.
+func indirect(v reflect.Value) reflect.Value {
+	for {
+		switch v.Kind() {
+		default:
+			return v
+		case reflect.Ptr:
+			v = reflect.Indirect(v)
+		case reflect.Interface:
+			if v.IsNil() {
+				return v
+			}
+			v = v.Elem()
+		}
+	}
+}
+
+// A KeyFunc returns the value to sort by for a single element of a slice.
+// Unlike a Getter, which retrieves the values for all the elements of a
+// slice at once, a KeyFunc lets them be retrieved one at a time, as they are
+// needed, e.g. when finding the largest element of a large slice.
+type KeyFunc func(elem reflect.Value) reflect.Value
+
+// Returns a Getter which calls k for each element of a slice.
+func (k KeyFunc) Getter() Getter {
 	return func(s reflect.Value) []reflect.Value {
 		vals := valueSlice(s.Len())
 		for i := range vals {
-			vals[i] = reflect.Indirect(reflect.Indirect(s.Index(i)))
+			vals[i] = k(s.Index(i))
 		}
 		return vals
 	}
 }
 
+// Returns a KeyFunc which returns the element itself.
+func SimpleKey() KeyFunc {
+	return func(elem reflect.Value) reflect.Value {
+		return indirect(elem)
+	}
+}
+
+// Returns a KeyFunc which returns the field with name of a struct element.
+// A runtime panic will occur if the element has no such field, while a nil
+// pointer field gives an invalid value, which is placed like a nil.
+func FieldKey(name string) KeyFunc {
+	return func(elem reflect.Value) reflect.Value {
+		v := indirect(elem)
+		f := v.FieldByName(name)
+		if !f.IsValid() {
+			panic(fmt.Sprintf("Type %v has no field %s", v.Type(), name))
+		}
+		return indirect(f)
+	}
+}
+
+// Returns a Getter which returns the values from a reflect.Value for a
+// slice. This is the default Getter used if none is passed to Sort.
+func SimpleGetter() Getter {
+	return SimpleKey().Getter()
+}
+
 // Returns a Getter which gets fields with name from a reflect.Value for a
 // slice of a struct type, returning them as a slice of reflect.Value (one
 // Value for each field in each struct.) Can be used with Sort to sort an
-// []Object by e.g. Object.Name or Object.Date. A runtime panic will occur if
-// the specified field isn't exported.
+// []Object by e.g. Object.Name or Object.Date. Pointer fields, e.g. an *int,
+// are dereferenced, and nil pointers are placed according to the Sorter's
+// Policy. A runtime panic will occur if the specified field isn't exported.
 func FieldGetter(name string) Getter {
-	return func(s reflect.Value) []reflect.Value {
-		vals := valueSlice(s.Len())
-		for i := range vals {
-			vals[i] = reflect.Indirect(reflect.Indirect(s.Index(i)).FieldByName(name))
-		}
-		return vals
-	}
+	return FieldKey(name).Getter()
 }
 
 // Returns a Getter which gets nested fields corresponding to e.g.
@@ -53,12 +108,172 @@ func FieldByIndexGetter(index []int) Getter {
 	return func(s reflect.Value) []reflect.Value {
 		vals := valueSlice(s.Len())
 		for i := range vals {
-			vals[i] = reflect.Indirect(reflect.Indirect(s.Index(i)).FieldByIndex(index))
+			vals[i] = indirect(indirect(s.Index(i)).FieldByIndex(index))
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which gets the field f from a reflect.Value for a slice
+// of a struct type. f is typically retrieved once using ResolveField or
+// reflect.Type.FieldByName, so that the field lookup isn't repeated for
+// every sort.
+func StructFieldGetter(f reflect.StructField) Getter {
+	return FieldByIndexGetter(f.Index)
+}
+
+// Returns the index sequence of the field with name in the struct type t (or
+// the struct type t points to), for use with FieldByIndexGetter or
+// AscByFieldIndex. An error is returned if t isn't a struct type, or if it
+// doesn't have an exported field with the given name.
+func ResolveField(t reflect.Type, name string) ([]int, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Type %v is not a struct type", t)
+	}
+	f, ok := t.FieldByName(name)
+	if !ok {
+		return nil, fmt.Errorf("Type %v has no field %s", t, name)
+	}
+	if f.PkgPath != "" {
+		return nil, fmt.Errorf("Field %s of type %v is not exported", name, t)
+	}
+	return f.Index, nil
+}
+
+// Returns a Getter which gets the fields whose names match name
+// case-insensitively, e.g. the Name field for "name", from a reflect.Value for
+// a slice of a struct type. This is useful when name comes from e.g. a column
+// in a UI. A field whose name matches exactly is always used; otherwise, a
+// runtime panic will occur if no exported field matches, or if several do,
+// e.g. both ID and Id for "id".
+func FieldGetterCI(name string) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		var (
+			t     reflect.Type
+			index []int
+		)
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			v := indirect(s.Index(i))
+			if v.Type() != t {
+				t = v.Type()
+				var err error
+				if index, err = resolveFieldFold(t, name); err != nil {
+					panic(err.Error())
+				}
+			}
+			vals[i] = indirect(v.FieldByIndex(index))
+		}
+		return vals
+	}
+}
+
+// Like ResolveField, but matches name case-insensitively if t has no field
+// with exactly that name, and returns an error if the match is ambiguous.
+func resolveFieldFold(t reflect.Type, name string) ([]int, error) {
+	if index, err := ResolveField(t, name); err == nil {
+		return index, nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Type %v is not a struct type", t)
+	}
+	var match *reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || !strings.EqualFold(f.Name, name) {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("Field name %s is ambiguous in type %v; it matches both %s and %s", name, t, match.Name, f.Name)
+		}
+		match = &f
+	}
+	if match == nil {
+		return nil, fmt.Errorf("Type %v has no field %s", t, name)
+	}
+	return match.Index, nil
+}
+
+// Returns a Getter which gets the fields whose struct tag with the given key
+// has name from a reflect.Value for a slice of a struct type, e.g.
+// TagGetter("db", "created_at") for a field tagged `db:"created_at"`. Like
+// in encoding/json and encoding/xml, any options following a comma in the
+// tag are ignored, and a field with an empty name in its tag, or without the
+// tag, is known by its field name. Fields tagged "-" are ignored. A runtime
+// panic will occur if the struct type has no such exported field.
+func TagGetter(key, name string) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		var (
+			t     reflect.Type
+			index []int
+		)
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			v := indirect(s.Index(i))
+			if v.Type() != t {
+				t = v.Type()
+				index = tagFieldIndex(t, key, name)
+			}
+			vals[i] = indirect(v.FieldByIndex(index))
 		}
 		return vals
 	}
 }
 
+// Returns a Getter which gets the fields with the given name in their json
+// struct tags. See TagGetter.
+func JSONTagGetter(name string) Getter {
+	return TagGetter("json", name)
+}
+
+// Like JSONTagGetter, but parses the fields, which must be strings, as
+// numbers, like NumericGetter, e.g. for an amount decoded from JSON as a
+// string so that "2" sorts before "10". Values that aren't numbers are
+// placed like NaNs.
+func JSONTagNumericGetter(name string) Getter {
+	return NumericGetter(JSONTagGetter(name))
+}
+
+// Returns a Getter which gets the fields with the given name in their xml
+// struct tags, e.g. `xml:"id,attr"`. See TagGetter.
+func XMLTagGetter(name string) Getter {
+	return TagGetter("xml", name)
+}
+
+// Returns the index of the exported field in the struct type t whose struct
+// tag with the given key has name.
+func tagFieldIndex(t reflect.Type, key, name string) []int {
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Type %v is not a struct type", t))
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		n := f.Tag.Get(key)
+		if n == "-" {
+			continue
+		}
+		if c := strings.Index(n, ","); c != -1 {
+			n = n[:c]
+		}
+		if n == "" {
+			n = f.Name
+		}
+		if n == name {
+			return f.Index
+		}
+	}
+	panic(fmt.Sprintf("Type %v has no field with %s tag %q", t, key, name))
+}
+
 // Returns a Getter which gets values with index from a reflect.Value for a
 // slice. Can be used with Sort to sort an [][]int by e.g. the second element
 // in each nested slice.
@@ -66,8 +281,387 @@ func IndexGetter(index int) Getter {
 	return func(s reflect.Value) []reflect.Value {
 		vals := valueSlice(s.Len())
 		for i := range vals {
-			vals[i] = reflect.Indirect(reflect.Indirect(s.Index(i)).Index(index))
+			vals[i] = indirect(indirect(s.Index(i)).Index(index))
 		}
 		return vals
 	}
 }
+
+// Returns a Getter which gets the position in order of each of the values
+// retrieved by getter, or len(order) for values which aren't in order. Can be
+// used with Sort to sort a slice into the sequence given by order, e.g. a
+// list of categories, with unknown values last. The values in order must be
+// of the same types as the values retrieved by getter.
+func RankGetter(getter Getter, order []interface{}) Getter {
+	ranks := make(map[interface{}]int, len(order))
+	for i, v := range order {
+		k := mapKey(reflect.ValueOf(v))
+		if _, found := ranks[k]; !found {
+			ranks[k] = i
+		}
+	}
+	return func(s reflect.Value) []reflect.Value {
+		keys := getter(s)
+		vals := valueSlice(len(keys))
+		for i, k := range keys {
+			rank, found := ranks[mapKey(k)]
+			if !found {
+				rank = len(order)
+			}
+			vals[i] = reflect.ValueOf(rank)
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which gets the result of calling Error on the error fields
+// with name from a reflect.Value for a slice of a struct type. If name is
+// empty, the elements of the slice themselves are used, e.g. when sorting an
+// []error. nil errors are placed according to the Sorter's Policy.
+func ErrorGetter(name string) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			v := s.Index(i)
+			if name != "" {
+				v = indirect(v).FieldByName(name)
+			}
+			if isNil(v) {
+				vals[i] = v
+				continue
+			}
+			vals[i] = reflect.ValueOf(v.Interface().(error).Error())
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which parses the strings retrieved by getter as floating
+// point numbers, e.g. to sort "2" before "10". Strings that aren't numbers
+// are returned as NaN, and are placed according to the Sorter's Policy.
+func NumericGetter(getter Getter) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := getter(s)
+		for i, v := range vals {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+			if err != nil {
+				f = math.NaN()
+			}
+			vals[i] = reflect.ValueOf(f)
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which formats the values retrieved by getter with
+// fmt.Sprintf("%+v"), e.g. "{X:1 Y:2}" for a struct, as a last resort for
+// sorting by values that can't otherwise be compared, like small structs,
+// without registering a comparison function with RegisterType. The order is
+// deterministic, but not meaningful: numbers are compared as strings, e.g.
+// "10" before "9", and values containing pointers are compared by the
+// addresses they print as, which change between runs. Nil values remain nil.
+// getter may be nil to format the elements themselves.
+func ReprGetter(getter Getter) Getter {
+	if getter == nil {
+		getter = SimpleGetter()
+	}
+	return func(s reflect.Value) []reflect.Value {
+		vals := getter(s)
+		for i, v := range vals {
+			if !isNil(v) {
+				vals[i] = reflect.ValueOf(fmt.Sprintf("%+v", v.Interface()))
+			}
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which formats the numbers retrieved by getter with
+// fmt.Sprintf(format), e.g. "%08.2f", so that they are compared as strings,
+// e.g. to match the order of an external system that sorts numbers as text.
+// This is the opposite of NumericGetter: with the format "%d", 100 sorts
+// before 20, while with "%05d", it sorts after it. If format is empty, "%v"
+// is used. Values that aren't numbers are left as they are. getter may be nil
+// to format the elements themselves.
+func TextualNumericGetter(getter Getter, format string) Getter {
+	if getter == nil {
+		getter = SimpleGetter()
+	}
+	if format == "" {
+		format = "%v"
+	}
+	return func(s reflect.Value) []reflect.Value {
+		vals := getter(s)
+		for i, v := range vals {
+			switch k := v.Kind(); {
+			case isInt(k), isUint(k), k == reflect.Float32, k == reflect.Float64:
+				vals[i] = reflect.ValueOf(fmt.Sprintf(format, v.Interface()))
+			}
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which gets the number of exported fields that aren't the
+// zero value of their type, e.g. 0, "", false or nil, from a reflect.Value
+// for a slice of a struct type, i.e. how complete each struct is. Nested
+// structs count as one field, which is zero only if all of their fields
+// are. Unexported fields are ignored. A runtime panic will occur if the
+// elements aren't structs.
+func CompletenessGetter() Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			v := indirect(s.Index(i))
+			if !v.IsValid() {
+				continue
+			}
+			if v.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("Cannot count the fields of type %v; it isn't a struct type", v.Type()))
+			}
+			n := 0
+			for f := 0; f < v.NumField(); f++ {
+				if v.Type().Field(f).PkgPath == "" && !v.Field(f).IsZero() {
+					n++
+				}
+			}
+			vals[i] = reflect.ValueOf(n)
+		}
+		return vals
+	}
+}
+
+// A Reducer decides which of the values retrieved from a child slice is used
+// to sort by in ChildGetter.
+type Reducer int
+
+const (
+	// ReduceMin uses the smallest value.
+	ReduceMin Reducer = iota
+	// ReduceMax uses the largest value.
+	ReduceMax
+)
+
+// Returns a Getter which gets one value for each child slice in the fields
+// with name from a reflect.Value for a slice of a struct type, by retrieving
+// the values of the child slice's elements using sub, and reducing them to
+// the smallest or largest one, e.g. ChildGetter("Children", FieldGetter("Id"),
+// ReduceMin) to sort parents by the smallest Id of their children. If name is
+// empty, the elements of the slice are the child slices. sub may be nil to
+// use the elements of the child slices themselves. Nil values are ignored,
+// and child slices without any other values get a nil value, which is placed
+// according to the Sorter's Policy.
+func ChildGetter(name string, sub Getter, reduce Reducer) Getter {
+	if sub == nil {
+		sub = SimpleGetter()
+	}
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		less := (&Sorter{}).lessFunc(nil)
+		for i := range vals {
+			child := indirect(s.Index(i))
+			if name != "" {
+				child = indirect(child.FieldByName(name))
+			}
+			if !child.IsValid() || child.Len() == 0 {
+				continue
+			}
+			for _, v := range sub(child) {
+				if isNil(v) {
+					continue
+				}
+				if !vals[i].IsValid() || reduce == ReduceMax && less(vals[i], v) || reduce == ReduceMin && less(v, vals[i]) {
+					vals[i] = v
+				}
+			}
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which gets the value for key in the map fields with name
+// from a reflect.Value for a slice of a struct type, e.g. to sort by
+// Attributes["priority"]. Elements whose maps don't contain key get a nil
+// value, which is placed according to the Sorter's Policy. A runtime panic
+// will occur if the field isn't a map, or if key can't be converted to the
+// map's key type.
+func MapFieldGetter(name string, key interface{}) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		var k reflect.Value
+		for i := range vals {
+			m := mapField(s.Index(i), name)
+			if !k.IsValid() {
+				k = reflect.ValueOf(key)
+				if !k.IsValid() || !k.Type().ConvertibleTo(m.Type().Key()) {
+					panic(fmt.Sprintf("Cannot use %v as a key of field %s of type %v", key, name, m.Type()))
+				}
+				k = k.Convert(m.Type().Key())
+			}
+			vals[i] = indirect(m.MapIndex(k))
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which gets the number of entries in the map fields with
+// name from a reflect.Value for a slice of a struct type. A runtime panic
+// will occur if the field isn't a map.
+func MapLenGetter(name string) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			vals[i] = reflect.ValueOf(mapField(s.Index(i), name).Len())
+		}
+		return vals
+	}
+}
+
+func mapField(elem reflect.Value, name string) reflect.Value {
+	f := indirect(elem).FieldByName(name)
+	if f.Kind() != reflect.Map {
+		panic(fmt.Sprintf("Field %s of type %v is not a map", name, f.Type()))
+	}
+	return f
+}
+
+// Returns a Getter which gets the sum of the elements of each child slice (or
+// array) from a reflect.Value for a slice of slices, e.g. the row sums of an
+// [][]int. Sums of integers are int64s, sums of unsigned integers are
+// uint64s, and sums of floats are float64s. A runtime panic will occur if
+// the elements of the child slices aren't numbers.
+func SumGetter() Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		t := s.Type().Elem()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if k := t.Kind(); k != reflect.Slice && k != reflect.Array {
+			panic(fmt.Sprintf("Cannot sum elements of type %v", t))
+		}
+		k := t.Elem().Kind()
+		for i := range vals {
+			v := indirect(s.Index(i))
+			l := 0
+			if v.IsValid() {
+				l = v.Len()
+			}
+			switch {
+			case isInt(k):
+				var sum int64
+				for j := 0; j < l; j++ {
+					sum += v.Index(j).Int()
+				}
+				vals[i] = reflect.ValueOf(sum)
+			case isUint(k):
+				var sum uint64
+				for j := 0; j < l; j++ {
+					sum += v.Index(j).Uint()
+				}
+				vals[i] = reflect.ValueOf(sum)
+			case k == reflect.Float32 || k == reflect.Float64:
+				var sum float64
+				for j := 0; j < l; j++ {
+					sum += v.Index(j).Float()
+				}
+				vals[i] = reflect.ValueOf(sum)
+			default:
+				panic(fmt.Sprintf("Cannot sum elements of type %v", t))
+			}
+		}
+		return vals
+	}
+}
+
+// Returns a Getter which gets a 64-bit FNV-1a hash of the seed and the
+// fields with name from a reflect.Value for a slice of a struct type. If name
+// is empty, the elements of the slice themselves are hashed. Sorting by the
+// hashes puts the elements in a pseudo-random order which is the same every
+// time for the same seed and values, e.g. to spread load deterministically.
+// Values are hashed by their formatted representation (as with fmt.Sprint),
+// except for strings, byte slices, and integers, which are hashed directly.
+func HashGetter(name string, seed uint64) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		buf := make([]byte, 8)
+		for i := range vals {
+			v := indirect(s.Index(i))
+			if name != "" {
+				v = indirect(v.FieldByName(name))
+			}
+			h := fnv.New64a()
+			binary.LittleEndian.PutUint64(buf, seed)
+			h.Write(buf)
+			switch {
+			case !v.IsValid():
+			case v.Kind() == reflect.String:
+				h.Write([]byte(v.String()))
+			case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+				h.Write(v.Bytes())
+			case isInt(v.Kind()):
+				binary.LittleEndian.PutUint64(buf, uint64(v.Int()))
+				h.Write(buf)
+			case isUint(v.Kind()):
+				binary.LittleEndian.PutUint64(buf, v.Uint())
+				h.Write(buf)
+			default:
+				fmt.Fprint(h, v.Interface())
+			}
+			vals[i] = reflect.ValueOf(h.Sum64())
+		}
+		return vals
+	}
+}
+
+// A TimeComponent identifies a part of a time.Time to sort by.
+type TimeComponent int
+
+const (
+	TimeComponentTimeOfDay TimeComponent = iota // Clock time, ignoring the date
+	TimeComponentHour                           // Hour of the day
+	TimeComponentWeekday                        // Day of the week, starting on Sunday
+	TimeComponentDay                            // Day of the month
+	TimeComponentMonth                          // Month of the year
+	TimeComponentYearDay                        // Day of the year
+)
+
+// Returns a Getter which gets the given component, e.g. the time of day with
+// TimeComponentTimeOfDay, of the time.Time fields with name from a
+// reflect.Value for a slice of a struct type. If name is empty, the elements
+// of the slice themselves are used, e.g. when sorting a []time.Time. Can be
+// used with Sort to order events by when they happen during the day,
+// regardless of their date. A runtime panic will occur if the specified field
+// isn't a time.Time.
+func TimeComponentGetter(name string, component TimeComponent) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			v := indirect(s.Index(i))
+			if name != "" {
+				v = indirect(v.FieldByName(name))
+			}
+			vals[i] = reflect.ValueOf(timeComponent(v.Interface().(time.Time), component))
+		}
+		return vals
+	}
+}
+
+func timeComponent(t time.Time, component TimeComponent) int64 {
+	switch component {
+	case TimeComponentTimeOfDay:
+		h, m, s := t.Clock()
+		return int64(h)*int64(time.Hour) + int64(m)*int64(time.Minute) + int64(s)*int64(time.Second) + int64(t.Nanosecond())
+	case TimeComponentHour:
+		return int64(t.Hour())
+	case TimeComponentWeekday:
+		return int64(t.Weekday())
+	case TimeComponentDay:
+		return int64(t.Day())
+	case TimeComponentMonth:
+		return int64(t.Month())
+	case TimeComponentYearDay:
+		return int64(t.YearDay())
+	}
+	panic(fmt.Sprintf("Invalid time component %d", component))
+}
@@ -0,0 +1,108 @@
+package sortutil
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Sort a slice in ascending order by a field name, and return the runs of
+// elements whose fields are equal, in sorted order, as a slice of slices of
+// the same type, e.g. a [][]Item for an []Item. The groups share the
+// underlying array of the sorted slice.
+func GroupByField(slice interface{}, name string) interface{} {
+	s := New(slice, FieldGetter(name), Ascending)
+	s.Sort()
+	t := s.Slice.Type()
+	if t.Kind() == reflect.Array {
+		t = reflect.SliceOf(t.Elem())
+	}
+	groups := reflect.MakeSlice(reflect.SliceOf(t), 0, 0)
+	l := s.Slice.Len()
+	if l == 0 {
+		return groups.Interface()
+	}
+	vals := s.Getter(s.Slice)
+	less := (&Sorter{Ordering: Ascending}).lessFunc(vals)
+	start := 0
+	for i := 1; i <= l; i++ {
+		if i == l || less(vals[start], vals[i]) {
+			groups = reflect.Append(groups, s.Slice.Slice(start, i))
+			start = i
+		}
+	}
+	return groups.Interface()
+}
+
+// Returns the distinct values of a field name across a slice, in the order
+// specified by ordering, e.g. to list the choices for a filter. Values are
+// distinct if neither sorts before the other, so e.g. "a" and "A" are
+// considered the same value with a case-insensitive ordering, and only one
+// of them is returned. The slice isn't modified. Nil values are returned as
+// nil.
+func DistinctByField(slice interface{}, name string, ordering Ordering) []interface{} {
+	s := New(slice, FieldGetter(name), ordering)
+	distinct := []interface{}{}
+	if s.Slice.Len() == 0 {
+		return distinct
+	}
+	s.sortValues()
+	less := (&Sorter{Ordering: s.Ordering}).lessFunc(s.vals)
+	for i, v := range s.vals {
+		if i > 0 && !less(s.vals[i-1], v) {
+			continue
+		}
+		if isNil(v) {
+			distinct = append(distinct, nil)
+		} else {
+			distinct = append(distinct, v.Interface())
+		}
+	}
+	return distinct
+}
+
+// Sort a slice in ascending order using a Getter, and return its elements in
+// buckets by where their values fall among edges, which must be in ascending
+// order, as a slice of slices of the same type, e.g. a [][]int for an []int.
+// There are len(edges)+1 buckets: the first holds the elements whose values
+// are less than edges[0], bucket i holds those whose values are at least
+// edges[i-1] and less than edges[i], and the last holds those whose values
+// are at least the last edge. Buckets may be empty, and share the underlying
+// array of the sorted slice. getter may be nil to use the elements
+// themselves. A runtime panic will occur if edges aren't sorted, or can't be
+// compared with the values.
+func BucketByRanges(slice interface{}, getter Getter, edges []interface{}) interface{} {
+	s := New(slice, getter, Ascending)
+	s.Sort()
+	if s.Getter == nil {
+		s.Getter = SimpleGetter()
+	}
+	t := s.Slice.Type()
+	if t.Kind() == reflect.Array {
+		t = reflect.SliceOf(t.Elem())
+	}
+	var vals []reflect.Value
+	if s.Slice.Len() > 0 {
+		vals = s.Getter(s.Slice)
+	}
+	bounds := valueSlice(len(edges))
+	for i, e := range edges {
+		bounds[i] = reflect.ValueOf(e)
+	}
+	less := (&Sorter{Ordering: Ascending}).lessFunc(append(bounds, vals...))
+	for i := 1; i < len(bounds); i++ {
+		if less(bounds[i], bounds[i-1]) {
+			panic(fmt.Sprintf("Edges are not in ascending order: %v comes after %v", edges[i], edges[i-1]))
+		}
+	}
+	buckets := reflect.MakeSlice(reflect.SliceOf(t), 0, len(edges)+1)
+	start, end := 0, 0
+	for _, b := range bounds {
+		for end < len(vals) && less(vals[end], b) {
+			end++
+		}
+		buckets = reflect.Append(buckets, s.Slice.Slice(start, end))
+		start = end
+	}
+	buckets = reflect.Append(buckets, s.Slice.Slice(start, s.Slice.Len()))
+	return buckets.Interface()
+}
@@ -0,0 +1,98 @@
+package sortutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Inserts elem into the slice slicePtr points to, which must already be
+// sorted using getter in the order specified by ordering, such that it stays
+// sorted, and returns the index elem was inserted at. If the slice already
+// has an element that is equal to elem, nothing is inserted and the index of
+// that element is returned instead, so inserting the same element twice has
+// no further effect. The insertion point is found using a
+// binary search, so the getter is only called for O(log n) elements, and the
+// slice is grown like with append. A runtime panic will occur if slicePtr
+// isn't a pointer to a slice, or if elem can't be assigned to its elements.
+func SortedInsert(slicePtr interface{}, elem interface{}, getter Getter, ordering Ordering) int {
+	p := reflect.ValueOf(slicePtr)
+	if p.Kind() != reflect.Ptr || p.Elem().Kind() != reflect.Slice {
+		panic(fmt.Sprintf("SortedInsert needs a pointer to a slice, not %T", slicePtr))
+	}
+	sv := p.Elem()
+	one := reflect.MakeSlice(sv.Type(), 1, 1)
+	one.Index(0).Set(reflect.ValueOf(elem))
+	if getter == nil {
+		getter = SimpleGetter()
+	}
+	key := getter(one)[0]
+	less := (&Sorter{Ordering: ordering}).lessFunc([]reflect.Value{key})
+	i := sort.Search(sv.Len(), func(i int) bool {
+		return less(key, getter(sv.Slice(i, i+1))[0])
+	})
+	if i > 0 && !less(getter(sv.Slice(i-1, i))[0], key) {
+		// The element before the insertion point is equal to elem
+		return i - 1
+	}
+	sv.Set(reflect.AppendSlice(sv, one))
+	reflect.Copy(sv.Slice(i+1, sv.Len()), sv.Slice(i, sv.Len()-1))
+	sv.Index(i).Set(one.Index(0))
+	return i
+}
+
+// Removes the elements of the slice slicePtr points to which are nil, e.g.
+// nil pointers, or whose values retrieved using getter are nil or the zero
+// value of their type, e.g. 0 or "", and sorts the remaining elements in the
+// order specified by ordering. The slice is shortened in place, and the
+// elements past its new length are cleared. getter may be nil to use the
+// elements themselves. A runtime panic will occur if slicePtr isn't a pointer
+// to a slice.
+func SortCompact(slicePtr interface{}, getter Getter, ordering Ordering) {
+	p := reflect.ValueOf(slicePtr)
+	if p.Kind() != reflect.Ptr || p.Elem().Kind() != reflect.Slice {
+		panic(fmt.Sprintf("SortCompact needs a pointer to a slice, not %T", slicePtr))
+	}
+	sv := p.Elem()
+	if getter == nil {
+		getter = SimpleGetter()
+	}
+	l := sv.Len()
+	// Remove nil elements first, since the getter can't be applied to them
+	compact(sv, func(i int) bool {
+		return !isNil(sv.Index(i))
+	})
+	if sv.Len() > 0 {
+		vals := getter(sv)
+		compact(sv, func(i int) bool {
+			return !isNil(vals[i]) && !vals[i].IsZero()
+		})
+	}
+	removed := sv.Slice(sv.Len(), l)
+	zero := reflect.Zero(sv.Type().Elem())
+	for i := 0; i < removed.Len(); i++ {
+		removed.Index(i).Set(zero)
+	}
+	s := &Sorter{
+		Slice:    sv,
+		Getter:   getter,
+		Ordering: ordering,
+	}
+	s.Sort()
+}
+
+// Moves the elements of sv for which keep returns true to the beginning of
+// sv, in their original order, and shortens sv to them.
+func compact(sv reflect.Value, keep func(i int) bool) {
+	n := 0
+	for i := 0; i < sv.Len(); i++ {
+		if !keep(i) {
+			continue
+		}
+		if n != i {
+			sv.Index(n).Set(sv.Index(i))
+		}
+		n++
+	}
+	sv.SetLen(n)
+}
@@ -0,0 +1,111 @@
+package sortutil
+
+import (
+	"container/heap"
+	"fmt"
+	"reflect"
+)
+
+// Merge slices of the same type, each of which is already sorted according
+// to the values retrieved by getter in the given ordering, into a new sorted
+// slice of that type. Elements with equal values are taken from the earlier
+// slices first. getter may be nil if the slices are of a basic type. Any of
+// the slices may be pointers to slices. A runtime panic will occur if no
+// slices are given, if they're of different types, or if their values can't
+// be compared.
+func MergeSorted(ordering Ordering, getter Getter, slices ...interface{}) interface{} {
+	if len(slices) == 0 {
+		panic("No slices to merge")
+	}
+	if getter == nil {
+		getter = SimpleGetter()
+	}
+	m := &merger{
+		keys: make([][]reflect.Value, len(slices)),
+	}
+	var (
+		t     reflect.Type
+		total int
+		all   []reflect.Value
+	)
+	svs := make([]reflect.Value, len(slices))
+	for k, v := range slices {
+		sv := New(v, getter, ordering).Slice
+		if t == nil {
+			t = sv.Type()
+		} else if sv.Type() != t {
+			panic(fmt.Sprintf("Cannot merge a %v with a %v", sv.Type(), t))
+		}
+		svs[k] = sv
+		m.keys[k] = getter(sv)
+		all = append(all, m.keys[k]...)
+		total += sv.Len()
+		if sv.Len() > 0 {
+			m.cursors = append(m.cursors, cursor{k, 0})
+		}
+	}
+	if t.Kind() == reflect.Array {
+		t = reflect.SliceOf(t.Elem())
+	}
+	res := reflect.MakeSlice(t, 0, total)
+	if total == 0 {
+		return res.Interface()
+	}
+	m.less = (&Sorter{Getter: getter, Ordering: ordering}).lessFunc(all)
+	heap.Init(m)
+	for m.Len() > 0 {
+		c := m.cursors[0]
+		res = reflect.Append(res, svs[c.slice].Index(c.index))
+		if c.index+1 < len(m.keys[c.slice]) {
+			m.cursors[0].index++
+			heap.Fix(m, 0)
+		} else {
+			heap.Pop(m)
+		}
+	}
+	return res.Interface()
+}
+
+// A position in one of the slices being merged.
+type cursor struct {
+	slice int
+	index int
+}
+
+// A heap.Interface of cursors, ordered by the values they point to.
+type merger struct {
+	keys    [][]reflect.Value
+	cursors []cursor
+	less    func(a, b reflect.Value) bool
+}
+
+func (m *merger) Len() int {
+	return len(m.cursors)
+}
+
+func (m *merger) Less(i, j int) bool {
+	a, b := m.cursors[i], m.cursors[j]
+	x, y := m.keys[a.slice][a.index], m.keys[b.slice][b.index]
+	if m.less(x, y) {
+		return true
+	}
+	if m.less(y, x) {
+		return false
+	}
+	return a.slice < b.slice
+}
+
+func (m *merger) Swap(i, j int) {
+	m.cursors[i], m.cursors[j] = m.cursors[j], m.cursors[i]
+}
+
+func (m *merger) Push(x interface{}) {
+	m.cursors = append(m.cursors, x.(cursor))
+}
+
+func (m *merger) Pop() interface{} {
+	l := len(m.cursors)
+	c := m.cursors[l-1]
+	m.cursors = m.cursors[:l-1]
+	return c
+}
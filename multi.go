@@ -0,0 +1,209 @@
+package sortutil
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// A KeySpec describes one of the keys to sort by in a multi-key sort: the
+// Getter used to retrieve the values, the Ordering to compare them in, and
+// the Policy deciding where nil, empty and NaN values of the key are placed
+// (among the elements whose previous keys are equal). Getter may be nil to
+// sort by the elements themselves.
+type KeySpec struct {
+	Getter   Getter
+	Ordering Ordering
+	Policy   Policy
+}
+
+// Sort a slice by several keys, e.g. by last name, then by first name.
+// Elements are ordered by the first key; elements whose first keys are equal
+// are ordered by the second key, and so on. Elements for which all keys are
+// equal keep their original relative order. A runtime panic will occur if
+// any of the keys' Getters aren't applicable to the slice, or if their
+// values can't be compared.
+func MultiSort(slice interface{}, keys ...KeySpec) {
+	s := New(slice, nil, Ascending)
+	l := s.Slice.Len()
+	if l < 2 || len(keys) == 0 {
+		return
+	}
+	s.itemType = s.Slice.Index(0).Type()
+	s.perm = identity(l)
+	m := multi{
+		Sorter: s,
+		vals:   make([][]reflect.Value, len(keys)),
+		less:   make([]func(a, b reflect.Value) bool, len(keys)),
+	}
+	for k, key := range keys {
+		g := key.Getter
+		if g == nil {
+			g = SimpleGetter()
+		}
+		m.vals[k] = g(s.Slice)
+		if len(m.vals[k]) == 0 {
+			panic(errNoValues)
+		}
+		for i, v := range m.vals[k] {
+			if _, ok := key.Policy.place(v, key.Ordering); !ok {
+				panic(fmt.Sprintf("Value %v at index %d is not allowed by the sort policy of key %d", describe(v), i, k))
+			}
+		}
+		m.less[k] = (&Sorter{Ordering: key.Ordering, Policy: key.Policy}).lessFunc(m.vals[k])
+	}
+	sort.Stable(m)
+	s.permute()
+}
+
+// Sort a slice using a Getter in the order specified by Ordering, ordering
+// elements whose values are equal by priorities, where priorities[i] is the
+// priority of the element at index i, and elements with lower priorities
+// come first. A runtime panic will occur if priorities doesn't have the same
+// length as the slice.
+func SortWithTieBreak(slice interface{}, getter Getter, ordering Ordering, priorities []int) {
+	s := New(slice, getter, ordering)
+	if len(priorities) != s.Slice.Len() {
+		panic(fmt.Sprintf("Got %d priorities for slice of length %d", len(priorities), s.Slice.Len()))
+	}
+	byPriority := func(reflect.Value) []reflect.Value {
+		vals := valueSlice(len(priorities))
+		for i, p := range priorities {
+			vals[i] = reflect.ValueOf(p)
+		}
+		return vals
+	}
+	MultiSort(slice, KeySpec{Getter: getter, Ordering: ordering}, KeySpec{Getter: byPriority})
+}
+
+// Sort a slice using a Getter in the order specified by Ordering, shuffling
+// the elements whose values are equal pseudo-randomly, e.g. to take turns
+// fairly between items with the same priority. The shuffle is reproducible:
+// sorting the same slice with the same seed always gives the same order.
+func SortTieShuffle(slice interface{}, getter Getter, ordering Ordering, seed int64) {
+	s := New(slice, getter, ordering)
+	if s.Slice.Len() < 2 {
+		return
+	}
+	s.setup()
+	l := s.lesser()
+	sort.Stable(l)
+	r := rand.New(rand.NewSource(seed))
+	for i, n := 0, l.Len(); i < n; {
+		j := i + 1
+		for j < n && !l.Less(j-1, j) {
+			j++
+		}
+		r.Shuffle(j-i, func(a, b int) {
+			l.Swap(i+a, i+b)
+		})
+		i = j
+	}
+	s.permute()
+}
+
+// Sort a slice of times, or of structs with a time.Time field retrieved by
+// getter, grouping them by the names of their locations, e.g. "UTC" or
+// "Asia/Tokyo", in ascending order, then by instant within each location in
+// the order specified by ordering. A runtime panic will occur if the values
+// retrieved by getter aren't of type time.Time.
+func ByZoneThenTime(slice interface{}, getter Getter, ordering Ordering) {
+	if getter == nil {
+		getter = SimpleGetter()
+	}
+	MultiSort(slice, KeySpec{Getter: zoneGetter(getter)}, KeySpec{Getter: getter, Ordering: ordering})
+}
+
+// Returns a Getter which returns the location names of the times retrieved
+// by getter.
+func zoneGetter(getter Getter) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := getter(s)
+		zones := valueSlice(len(vals))
+		for i, v := range vals {
+			t, ok := v.Interface().(time.Time)
+			if !ok {
+				panic(fmt.Sprintf("Cannot group value %v at index %d by zone; it isn't a time.Time", describe(v), i))
+			}
+			zones[i] = reflect.ValueOf(t.Location().String())
+		}
+		return zones
+	}
+}
+
+// A SortSpec describes one of the columns to sort by in SortBySpecs: the name
+// of a struct field, and the direction to sort it in, as understood by
+// ParseOrdering, e.g. "asc" or "ci-desc".
+type SortSpec struct {
+	Field     string
+	Direction string
+}
+
+// Sort a slice of structs by several fields like MultiSort, e.g. using the
+// column and direction pairs of a table sorted by the user. An error is
+// returned, and the slice isn't modified, if any of the directions are
+// invalid, if the struct type doesn't have one of the fields, or if a field
+// can't be sorted in its direction, e.g. because the direction is
+// case-insensitive and the field isn't a string, or if slice isn't a slice or
+// a pointer to one. See ValidateField.
+func SortBySpecs(slice interface{}, specs []SortSpec) error {
+	s := New(slice, nil, Ascending)
+	if k := s.Slice.Kind(); k != reflect.Slice && k != reflect.Array {
+		return fmt.Errorf("Cannot sort type %T; expected a slice or a pointer to a slice or array", slice)
+	}
+	keys := make([]KeySpec, len(specs))
+	for i, spec := range specs {
+		o, err := ParseOrdering(spec.Direction)
+		if err != nil {
+			return fmt.Errorf("Invalid direction for field %s: %v", spec.Field, err)
+		}
+		if slice == nil {
+			// There's nothing to sort, and no struct type to check the
+			// fields of
+			continue
+		}
+		index, err := ResolveField(s.Slice.Type().Elem(), spec.Field)
+		if err != nil {
+			return err
+		}
+		if err := ValidateField(slice, spec.Field, o); err != nil {
+			return err
+		}
+		keys[i] = KeySpec{Getter: FieldByIndexGetter(index), Ordering: o}
+	}
+	MultiSort(slice, keys...)
+	return nil
+}
+
+// A sort.Interface comparing several keys in turn.
+type multi struct {
+	*Sorter
+	vals [][]reflect.Value // The values of each key
+	less []func(a, b reflect.Value) bool
+}
+
+func (m multi) Len() int {
+	return len(m.Sorter.perm)
+}
+
+func (m multi) Less(i, j int) bool {
+	for k, vals := range m.vals {
+		a, b := vals[i], vals[j]
+		if m.less[k](a, b) {
+			return true
+		}
+		if m.less[k](b, a) {
+			return false
+		}
+	}
+	return false
+}
+
+func (m multi) Swap(i, j int) {
+	for _, vals := range m.vals {
+		vals[i], vals[j] = vals[j], vals[i]
+	}
+	m.Sorter.perm[i], m.Sorter.perm[j] = m.Sorter.perm[j], m.Sorter.perm[i]
+}
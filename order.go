@@ -0,0 +1,70 @@
+package sortutil
+
+import (
+	"reflect"
+)
+
+// An Order records the order of the elements of a slice, so that it can be
+// restored after the slice (or a subset of it) has been sorted or shuffled.
+type Order struct {
+	getter    Getter
+	positions map[interface{}][]int
+	n         int // The number of elements recorded
+}
+
+// Records the order of the elements of a slice, identifying each element by
+// the value getter retrieves for it, e.g. FieldGetter("Id"). If getter is
+// nil, elements are identified by their values, which must then be
+// comparable; pointers identify the values they point to. Elements with equal
+// values are interchangeable, and keep their relative order when the order is
+// restored.
+func CaptureOrder(slice interface{}, getter Getter) *Order {
+	s := New(slice, getter, Ascending)
+	if s.Getter == nil {
+		s.Getter = SimpleGetter()
+	}
+	o := &Order{
+		getter:    s.Getter,
+		positions: make(map[interface{}][]int),
+		n:         s.Slice.Len(),
+	}
+	if s.Slice.Len() == 0 {
+		return o
+	}
+	for i, v := range s.Getter(s.Slice) {
+		k := mapKey(v)
+		o.positions[k] = append(o.positions[k], i)
+	}
+	return o
+}
+
+// Sorts a slice back into the order recorded by CaptureOrder. The slice may
+// contain only some of the elements that were recorded, e.g. after filtering
+// it; those that weren't recorded are moved to the end, in their current
+// relative order.
+func RestoreOrder(slice interface{}, o *Order) {
+	s := New(slice, nil, Ascending)
+	l := s.Slice.Len()
+	if l < 2 {
+		return
+	}
+	used := make(map[interface{}]int)
+	unknown := 0
+	s.Getter = func(sv reflect.Value) []reflect.Value {
+		vals := valueSlice(l)
+		for i, v := range o.getter(sv) {
+			k := mapKey(v)
+			var p int
+			if ps := o.positions[k]; used[k] < len(ps) {
+				p = ps[used[k]]
+				used[k]++
+			} else {
+				p = o.n + unknown
+				unknown++
+			}
+			vals[i] = reflect.ValueOf(p)
+		}
+		return vals
+	}
+	s.Sort()
+}
@@ -0,0 +1,114 @@
+package sortutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A step in a path: either a field name or an index.
+type pathStep struct {
+	field string
+	index int
+}
+
+// Returns a Getter which follows path from each element of a reflect.Value
+// for a slice, e.g. "Owner.Roles[0]" to get the first element of the Roles
+// field of the struct in the Owner field of each struct in the slice. Paths
+// consist of field names separated by dots, each optionally followed by one
+// or more indices in brackets, and may start with an index. Pointers and
+// interfaces are followed along the way; if a nil pointer is encountered,
+// the retrieved value is nil. An error is returned if path isn't valid.
+// The Getter panics if a field doesn't exist or an index is out of range.
+func PathGetter(path string) (Getter, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			vals[i] = followPath(indirect(s.Index(i)), steps, path)
+		}
+		return vals
+	}, nil
+}
+
+// Like PathGetter, but panics if path isn't valid.
+func MustPathGetter(path string) Getter {
+	g, err := PathGetter(path)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func parsePath(path string) ([]pathStep, error) {
+	var steps []pathStep
+	rest := path
+	if rest == "" {
+		return nil, fmt.Errorf("Empty path")
+	}
+	first := true
+	for rest != "" {
+		if !first || rest[0] != '[' {
+			if !first {
+				if rest[0] != '.' {
+					return nil, fmt.Errorf("Expected '.' or '[' at %q in path %q", rest, path)
+				}
+				rest = rest[1:]
+			}
+			end := strings.IndexAny(rest, ".[]")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("Missing field name at %q in path %q", rest, path)
+			}
+			steps = append(steps, pathStep{field: rest[:end]})
+			rest = rest[end:]
+		}
+		first = false
+		for rest != "" && rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("Missing ']' at %q in path %q", rest, path)
+			}
+			n, err := strconv.Atoi(rest[1:end])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("Invalid index %q in path %q", rest[1:end], path)
+			}
+			steps = append(steps, pathStep{index: n})
+			rest = rest[end+1:]
+		}
+	}
+	return steps, nil
+}
+
+func followPath(v reflect.Value, steps []pathStep, path string) reflect.Value {
+	for _, step := range steps {
+		if !v.IsValid() {
+			return v
+		}
+		if step.field != "" {
+			if v.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("Cannot get field %s of type %v in path %q", step.field, v.Type(), path))
+			}
+			f := v.FieldByName(step.field)
+			if !f.IsValid() {
+				panic(fmt.Sprintf("Type %v has no field %s in path %q", v.Type(), step.field, path))
+			}
+			v = indirect(f)
+			continue
+		}
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			panic(fmt.Sprintf("Cannot index type %v in path %q", v.Type(), path))
+		}
+		if step.index >= v.Len() {
+			panic(fmt.Sprintf("Index %d out of range for length %d in path %q", step.index, v.Len(), path))
+		}
+		v = indirect(v.Index(step.index))
+	}
+	return v
+}
@@ -0,0 +1,171 @@
+package sortutil
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// A Placement decides where values that need special treatment, e.g. nil
+// pointers, are placed in a sorted slice.
+type Placement int
+
+const (
+	// Natural places nils before all other values in ascending orderings
+	// and after them in descending orderings, and compares empty values
+	// and NaNs like any other value. NaNs are less than all other floats,
+	// as with cmp.Compare, so they come first in ascending orderings and
+	// last in descending ones.
+	Natural Placement = iota
+	// First places the values at the beginning of the slice, regardless
+	// of the ordering.
+	First
+	// Last places the values at the end of the slice, regardless of the
+	// ordering.
+	Last
+	// AsError causes a runtime panic if any of the values are encountered.
+	AsError
+)
+
+var placements = []string{
+	"Natural",
+	"First",
+	"Last",
+	"AsError",
+}
+
+func (p Placement) String() string {
+	return placements[p]
+}
+
+// A Policy decides where nil, empty and NaN values are placed when sorting.
+// Nils are nil pointers, interfaces, maps and slices, as well as the invalid
+// values returned by a Getter that dereferences a nil pointer. Empties are
+// empty strings and non-nil empty slices and maps. NaNs are float values
+// which are not a number. The zero Policy places all of them naturally.
+type Policy struct {
+	Nils    Placement
+	Empties Placement
+	NaNs    Placement
+}
+
+// Returns where v should be placed according to p when sorting in the given
+// ordering: -1 if it should be placed before all other values, 1 if it
+// should be placed after them, or 0 if it should be compared normally. ok is
+// false if p doesn't allow v.
+func (p Policy) place(v reflect.Value, ordering Ordering) (place int, ok bool) {
+	var pl Placement
+	switch {
+	case isNil(v):
+		pl = p.Nils
+		if pl == Natural {
+			if ordering.descending() || ordering.nonZeroFirst() {
+				return 1, true
+			}
+			return -1, true
+		}
+	case isEmpty(v):
+		pl = p.Empties
+	case isNaN(v):
+		pl = p.NaNs
+	}
+	switch pl {
+	case First:
+		return -1, true
+	case Last:
+		return 1, true
+	case AsError:
+		return 0, false
+	}
+	if ordering.nonZeroFirst() && v.IsZero() {
+		return 1, true
+	}
+	return 0, true
+}
+
+func isNil(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func isNaN(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(v.Float())
+	}
+	return false
+}
+
+// Returns a sort.Interface for s.vals which places values according to
+// s.Policy, and compares all other values in the order specified by
+// s.Ordering. A runtime panic will occur if s.Policy doesn't allow one of the
+// values.
+func (s *Sorter) lesser() sort.Interface {
+	t := s.typed()
+	places := make([]int, len(s.vals))
+	special := false
+	for i, v := range s.vals {
+		p, ok := s.Policy.place(v, s.Ordering)
+		if !ok {
+			panic(fmt.Sprintf("Value %v at index %d is not allowed by the sort policy", describe(v), s.perm[i]))
+		}
+		if t != nil && p == 0 && !s.compatible(v) {
+			panic(fmt.Sprintf("Cannot compare value %s of type %v at index %d with values of type %v", describe(v), v.Type(), s.perm[i], s.valType))
+		}
+		places[i] = p
+		special = special || p != 0
+	}
+	if !special {
+		return t
+	}
+	return placed{t, s, places}
+}
+
+// Returns a description of v for use in error messages.
+func describe(v reflect.Value) string {
+	switch {
+	case !v.IsValid() || isNil(v):
+		return "nil"
+	case isEmpty(v):
+		return fmt.Sprintf("%#v", v.Interface())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+type placed struct {
+	sort.Interface // nil if all values are placed
+	*Sorter
+	places []int
+}
+
+func (p placed) Len() int {
+	return p.Sorter.Len()
+}
+
+func (p placed) Less(i, j int) bool {
+	a, b := p.places[i], p.places[j]
+	if a != 0 || b != 0 {
+		return a < b
+	}
+	return p.Interface.Less(i, j)
+}
+
+func (p placed) Swap(i, j int) {
+	p.places[i], p.places[j] = p.places[j], p.places[i]
+	p.Sorter.Swap(i, j)
+}
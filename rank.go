@@ -0,0 +1,47 @@
+package sortutil
+
+import (
+	"fmt"
+)
+
+// A Ranking decides how elements with equal values are ranked by RanksByField.
+type Ranking int
+
+const (
+	// StandardRanking gives equal values the same rank, and leaves a gap
+	// after them, e.g. 1, 2, 2, 4 ("competition" ranking).
+	StandardRanking Ranking = iota
+	// DenseRanking gives equal values the same rank, without leaving a gap
+	// after them, e.g. 1, 2, 2, 3.
+	DenseRanking
+)
+
+// Returns the 1-based rank of each element of a slice of structs by the field
+// with name in the order specified by ordering, e.g. for a leaderboard, where
+// ranks[i] is the rank of the element at index i. The slice isn't reordered.
+// Elements whose fields are equal get the same rank, and ranking decides the
+// rank of the elements after them.
+func RanksByField(slice interface{}, name string, ordering Ordering, ranking Ranking) []int {
+	if ranking != StandardRanking && ranking != DenseRanking {
+		panic(fmt.Sprintf("Invalid ranking %d", int(ranking)))
+	}
+	s := New(slice, FieldGetter(name), ordering)
+	ranks := make([]int, s.Slice.Len())
+	if len(ranks) == 0 {
+		return ranks
+	}
+	s.sortValues()
+	less := (&Sorter{Ordering: s.Ordering}).lessFunc(s.vals)
+	rank := 1
+	for i, v := range s.vals {
+		if i > 0 && less(s.vals[i-1], v) {
+			if ranking == DenseRanking {
+				rank++
+			} else {
+				rank = i + 1
+			}
+		}
+		ranks[s.perm[i]] = rank
+	}
+	return ranks
+}
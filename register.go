@@ -0,0 +1,59 @@
+package sortutil
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[reflect.Type]func(a, b reflect.Value) int)
+)
+
+// Registers a comparison function for values of type t, which is used when
+// sorting by values of that type, e.g. a decimal type with a Cmp method:
+//
+//	sortutil.RegisterType(reflect.TypeOf(decimal.Decimal{}), func(a, b reflect.Value) int {
+//		return a.Interface().(decimal.Decimal).Cmp(b.Interface().(decimal.Decimal))
+//	})
+//
+// cmp returns a negative number if a sorts before b in ascending order, a
+// positive number if a sorts after b, and 0 if they are equal. Descending
+// orderings reverse it. Since pointers are dereferenced, the registration
+// also applies to fields of type *T. Registering a type which is otherwise
+// supported, e.g. time.Time, overrides the default comparison for it.
+// Registering a nil cmp removes the registration.
+func RegisterType(t reflect.Type, cmp func(a, b reflect.Value) int) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if cmp == nil {
+		delete(registry, t)
+		return
+	}
+	registry[t] = cmp
+}
+
+// Returns the comparison function registered for t, if any.
+func registered(t reflect.Type) func(a, b reflect.Value) int {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[t]
+}
+
+type registeredAscending struct {
+	*Sorter
+	cmp func(a, b reflect.Value) int
+}
+
+type registeredDescending struct {
+	*Sorter
+	cmp func(a, b reflect.Value) int
+}
+
+func (s registeredAscending) Less(i, j int) bool {
+	return s.cmp(s.Sorter.vals[i], s.Sorter.vals[j]) < 0
+}
+
+func (s registeredDescending) Less(i, j int) bool {
+	return s.cmp(s.Sorter.vals[i], s.Sorter.vals[j]) > 0
+}
@@ -0,0 +1,116 @@
+package sortutil
+
+import (
+	"reflect"
+)
+
+// Returns the element of a slice whose field with name is the smallest, or
+// nil if the slice is empty. Elements whose fields are nil are ignored. Only
+// one field is retrieved at a time, so unlike sorting, no memory is
+// allocated for the fields of all the elements.
+func MinByField(slice interface{}, name string) interface{} {
+	return extreme(slice, FieldKey(name), false)
+}
+
+// Returns the element of a slice whose field with name is the largest, or
+// nil if the slice is empty. See MinByField.
+func MaxByField(slice interface{}, name string) interface{} {
+	return extreme(slice, FieldKey(name), true)
+}
+
+// Returns the elements of a slice whose fields with name are the smallest and
+// the largest, scanning the slice once, e.g. to display the range of values
+// without sorting. Both are nil if the slice is empty. See MinByField.
+func MinMaxByField(slice interface{}, name string) (min, max interface{}) {
+	s := New(slice, nil, Ascending)
+	less := s.lessFunc(nil)
+	key := FieldKey(name)
+	lo, hi := -1, -1
+	var loKey, hiKey reflect.Value
+	for i := 0; i < s.Slice.Len(); i++ {
+		k := key(s.Slice.Index(i))
+		if isNil(k) {
+			continue
+		}
+		if lo == -1 {
+			lo, loKey, hi, hiKey = i, k, i, k
+			continue
+		}
+		if less(k, loKey) {
+			lo, loKey = i, k
+		} else if less(hiKey, k) {
+			hi, hiKey = i, k
+		}
+	}
+	if lo == -1 {
+		return nil, nil
+	}
+	return s.Slice.Index(lo).Interface(), s.Slice.Index(hi).Interface()
+}
+
+// Returns the element of a slice with the smallest (or largest, if largest is
+// true) value retrieved by key, scanning the slice once.
+func extreme(slice interface{}, key KeyFunc, largest bool) interface{} {
+	s := New(slice, nil, Ascending)
+	less := s.lessFunc(nil)
+	best := -1
+	var bestKey reflect.Value
+	for i := 0; i < s.Slice.Len(); i++ {
+		k := key(s.Slice.Index(i))
+		if isNil(k) {
+			continue
+		}
+		if best == -1 || largest && less(bestKey, k) || !largest && less(k, bestKey) {
+			best, bestKey = i, k
+		}
+	}
+	if best == -1 {
+		return nil
+	}
+	return s.Slice.Index(best).Interface()
+}
+
+// Returns a new slice with the (at most) n elements of a slice that would
+// come first if it was sorted using a Getter in the order specified by
+// Ordering, in sorted order. The slice itself isn't modified. Only n
+// elements and their values are kept while the slice is scanned, so this is
+// faster than sorting the whole slice when n is small.
+func TopN(slice interface{}, getter Getter, ordering Ordering, n int) interface{} {
+	return TopNWhere(slice, getter, ordering, n, nil)
+}
+
+// Like TopN, but only considers the elements for which pred returns true.
+// pred is passed each element of the slice, e.g. to select the 5 smallest
+// active items, before its value is retrieved, so getter is never applied to
+// elements pred rejects. If pred is nil, all the elements are considered.
+func TopNWhere(slice interface{}, getter Getter, ordering Ordering, n int, pred func(reflect.Value) bool) interface{} {
+	s := New(slice, getter, ordering)
+	t := s.Slice.Type()
+	if t.Kind() == reflect.Array {
+		t = reflect.SliceOf(t.Elem())
+	}
+	if s.Getter == nil {
+		s.Getter = SimpleGetter()
+	}
+	b := &BoundedSorter{K: n}
+	if n < 1 {
+		return b.sorted(t).Interface()
+	}
+	// Keys are retrieved one element at a time, and only for the elements
+	// pred accepts, so elements it rejects needn't have keys. Each element
+	// is copied to its own slice since the key may refer to it.
+	for i := 0; i < s.Slice.Len(); i++ {
+		elem := s.Slice.Index(i)
+		if pred != nil && !pred(elem) {
+			continue
+		}
+		one := reflect.MakeSlice(t, 1, 1)
+		one.Index(0).Set(elem)
+		k := s.Getter(one)[0]
+		if b.less == nil {
+			b.less = s.lessFunc([]reflect.Value{k})
+		}
+		b.push(elem, k)
+	}
+	return b.sorted(t).Interface()
+}
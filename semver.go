@@ -0,0 +1,130 @@
+package sortutil
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A parsed semantic version. Build metadata is discarded since it doesn't
+// affect precedence.
+type semver struct {
+	major, minor, patch uint64
+	pre                 []string
+}
+
+var t_semver = reflect.TypeOf(semver{})
+
+func init() {
+	RegisterType(t_semver, func(a, b reflect.Value) int {
+		return compareSemver(a.Interface().(semver), b.Interface().(semver))
+	})
+}
+
+// Returns a Getter which parses the strings retrieved by getter as semantic
+// versions, e.g. "1.2.3" or "v1.0.0-rc.1", and orders them by semantic
+// versioning precedence: by major, minor and patch version numerically, with
+// prereleases before the release they precede, so that "1.0.0-rc1" <
+// "1.0.0" < "1.2.0" < "1.10.0". Build metadata, e.g. "+20130313144700", is
+// ignored. Strings that aren't valid versions are returned as nil, and are
+// placed according to the Sorter's Policy; use AsError to reject them. getter
+// may be nil to parse the elements themselves.
+func SemverGetter(getter Getter) Getter {
+	if getter == nil {
+		getter = SimpleGetter()
+	}
+	return func(s reflect.Value) []reflect.Value {
+		vals := getter(s)
+		for i, v := range vals {
+			if sv, ok := parseSemver(v.String()); ok {
+				vals[i] = reflect.ValueOf(sv)
+			} else {
+				vals[i] = reflect.Value{}
+			}
+		}
+		return vals
+	}
+}
+
+func parseSemver(s string) (v semver, ok bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.pre = strings.Split(s[i+1:], ".")
+		for _, id := range v.pre {
+			if id == "" {
+				return v, false
+			}
+		}
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	nums := []*uint64{&v.major, &v.minor, &v.patch}
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return v, false
+		}
+		*nums[i] = n
+	}
+	return v, true
+}
+
+func compareSemver(a, b semver) int {
+	if c := compareUint(a.major, b.major); c != 0 {
+		return c
+	}
+	if c := compareUint(a.minor, b.minor); c != 0 {
+		return c
+	}
+	if c := compareUint(a.patch, b.patch); c != 0 {
+		return c
+	}
+	// A release has higher precedence than its prereleases
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.pre) && i < len(b.pre); i++ {
+		if c := comparePrerelease(a.pre[i], b.pre[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a.pre) - len(b.pre)
+}
+
+// Compares two prerelease identifiers. Numeric identifiers are compared
+// numerically, and have lower precedence than alphanumeric ones, which are
+// compared lexically.
+func comparePrerelease(a, b string) int {
+	x, errA := strconv.ParseUint(a, 10, 64)
+	y, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return compareUint(x, y)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
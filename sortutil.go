@@ -1,12 +1,17 @@
 package sortutil
 
 import (
+	"bytes"
+	"database/sql/driver"
 	"fmt"
 	"math"
+	"net/url"
 	"reflect"
 	"sort"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Ordering decides the order in which the specified data is sorted.
@@ -16,13 +21,53 @@ func (o Ordering) String() string {
 	return orderings[o]
 }
 
+func (o Ordering) descending() bool {
+	return o == Descending || o == CaseInsensitiveDescending || o == DescendingStableTies || o == NonZeroFirstDescending || o == ModularDescending
+}
+
+func (o Ordering) nonZeroFirst() bool {
+	return o == NonZeroFirstAscending || o == NonZeroFirstDescending
+}
+
+// Returns the ordering values are compared in, disregarding how zero values
+// and ties are placed.
+func (o Ordering) base() Ordering {
+	switch o {
+	case NonZeroFirstAscending:
+		return Ascending
+	case NonZeroFirstDescending, DescendingStableTies:
+		return Descending
+	}
+	return o
+}
+
 // A runtime panic will occur if case-insensitive is used when not sorting by
-// a string type.
+// a string, []byte or []rune type.
+//
+// DescendingStableTies sorts in descending order, but keeps elements that
+// are equal in their original relative order, i.e. in the same order they
+// would be in after a stable sort in ascending order, rather than in an
+// unspecified order.
+//
+// NonZeroFirstAscending and NonZeroFirstDescending place the values that are
+// the zero value of their type, e.g. 0, "" or a zero time.Time, as well as
+// nils, after all other values, and sort the values within each of the two
+// groups in ascending or descending order, respectively.
+//
+// ModularAscending and ModularDescending sort numbers by their distance
+// above the Sorter's Origin, modulo its Modulus, e.g. to sort angles
+// clockwise starting at 90 degrees with an Origin of 90 and a Modulus of
+// 360. They can only be used with a Sorter with a positive Modulus.
 const (
 	Ascending Ordering = iota
 	Descending
 	CaseInsensitiveAscending
 	CaseInsensitiveDescending
+	DescendingStableTies
+	NonZeroFirstAscending
+	NonZeroFirstDescending
+	ModularAscending
+	ModularDescending
 )
 
 var orderings = []string{
@@ -30,11 +75,43 @@ var orderings = []string{
 	"Descending",
 	"CaseInsensitiveAscending",
 	"CaseInsensitiveDescending",
+	"DescendingStableTies",
+	"NonZeroFirstAscending",
+	"NonZeroFirstDescending",
+	"ModularAscending",
+	"ModularDescending",
+}
+
+// Returns the Ordering described by s, e.g. "asc", "desc", "ci-asc" or
+// "ci-desc", as is common for sort directions in query strings. The long
+// forms "ascending" and "descending", and the names of the Orderings, e.g.
+// "CaseInsensitiveDescending", are also accepted. Case is ignored, and an
+// empty string means Ascending.
+func ParseOrdering(s string) (Ordering, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "asc", "ascending":
+		return Ascending, nil
+	case "desc", "descending":
+		return Descending, nil
+	case "ci-asc", "ci-ascending":
+		return CaseInsensitiveAscending, nil
+	case "ci-desc", "ci-descending":
+		return CaseInsensitiveDescending, nil
+	}
+	for i, name := range orderings {
+		if strings.EqualFold(s, name) {
+			return Ordering(i), nil
+		}
+	}
+	return Ascending, fmt.Errorf("Unknown ordering %q", s)
 }
 
 // Recognized non-standard types
 var (
 	t_time = reflect.TypeOf(time.Time{})
+	t_url  = reflect.TypeOf(url.URL{})
+
+	t_valuer = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
 )
 
 // A reflecting sort.Interface adapter.
@@ -42,10 +119,39 @@ type Sorter struct {
 	Slice    reflect.Value
 	Getter   Getter
 	Ordering Ordering
-	itemType reflect.Type    // Type of items being sorted
-	vals     []reflect.Value // Nested/child values that we're sorting by
-	valKind  reflect.Kind
-	valType  reflect.Type
+	// Policy decides where nil, empty and NaN values are placed.
+	Policy Policy
+	// If TimeResolution is positive, time.Time values are truncated to a
+	// multiple of it before being compared, e.g. so that times within the
+	// same second are considered equal if it is time.Second.
+	TimeResolution time.Duration
+	// If URLByHost is true, url.URL values are compared by their hosts,
+	// then by their paths, and only then by their string forms.
+	URLByHost bool
+	// If Normalize is set, string values are replaced with the result of
+	// Normalize before being compared, e.g. norm.NFC.String from
+	// golang.org/x/text/unicode/norm, so that canonically equivalent
+	// strings with different compositions compare equal, or
+	// CollapseSpace.
+	Normalize func(string) string
+	// Modulus and Origin are used by the ModularAscending and
+	// ModularDescending orderings, which compare numbers by
+	// (n - Origin) mod Modulus.
+	Modulus float64
+	Origin  float64
+	// If SkipErrors is true, elements for which the Getter panics, e.g.
+	// because they don't have the field being sorted by, or returns a value
+	// of a different type than for the other elements, are moved to the end
+	// of the slice (in their original order) instead of aborting the sort.
+	// Nil values, e.g. nil pointer fields, aren't skipped, and are placed
+	// according to Policy.
+	SkipErrors bool
+	itemType   reflect.Type    // Type of items being sorted
+	vals       []reflect.Value // Nested/child values that we're sorting by
+	perm       []int           // Original slice index of each of vals
+	skipped    []int           // Indices of elements that were skipped
+	valKind    reflect.Kind
+	valType    reflect.Type
 }
 
 // Sort the values in s.Slice by retrieving comparison items using
@@ -57,100 +163,551 @@ func (s *Sorter) Sort() {
 		// Nothing to sort
 		return
 	}
+	s.sortValues()
+	s.permute()
+}
+
+// Returns a copy of s with the same configuration, i.e. Slice, Getter,
+// Ordering, Policy and options, but none of the state of a sort in progress.
+// A Sorter mustn't be used by several goroutines at once, since sorting
+// modifies it, but each can use its own clone, e.g. with a different slice:
+//
+//	c := s.Clone()
+//	c.Slice = reflect.ValueOf(other)
+//	go c.Sort()
+func (s *Sorter) Clone() *Sorter {
+	return &Sorter{
+		Slice:          s.Slice,
+		Getter:         s.Getter,
+		Ordering:       s.Ordering,
+		Policy:         s.Policy,
+		TimeResolution: s.TimeResolution,
+		URLByHost:      s.URLByHost,
+		Normalize:      s.Normalize,
+		Modulus:        s.Modulus,
+		Origin:         s.Origin,
+		SkipErrors:     s.SkipErrors,
+	}
+}
+
+// Reverses the direction of s.Ordering, e.g. from Ascending to Descending or
+// from CaseInsensitiveDescending to CaseInsensitiveAscending, so that a
+// configured Sorter can be used to sort in both directions.
+// DescendingStableTies becomes Ascending, which keeps ties in the same order
+// too.
+func (s *Sorter) Flip() {
+	switch s.Ordering {
+	case Ascending:
+		s.Ordering = Descending
+	case Descending, DescendingStableTies:
+		s.Ordering = Ascending
+	case CaseInsensitiveAscending:
+		s.Ordering = CaseInsensitiveDescending
+	case CaseInsensitiveDescending:
+		s.Ordering = CaseInsensitiveAscending
+	case NonZeroFirstAscending:
+		s.Ordering = NonZeroFirstDescending
+	case NonZeroFirstDescending:
+		s.Ordering = NonZeroFirstAscending
+	case ModularAscending:
+		s.Ordering = ModularDescending
+	case ModularDescending:
+		s.Ordering = ModularAscending
+	default:
+		panic(fmt.Sprintf("Cannot flip invalid ordering %d", int(s.Ordering)))
+	}
+}
+
+// Sort the values in s.Slice stably like Sort, then reverse the order of the
+// values, keeping elements that are equal in their original relative order.
+// Unlike SortStableReverse with a sort.Interface, which reverses the order of
+// equal elements too, this gives a stable sort in the opposite direction of
+// s.Ordering, e.g. a stable descending sort if s.Ordering is Ascending.
+func (s *Sorter) SortStableReverse() {
+	if s.Slice.Len() < 2 {
+		return
+	}
+	s.setup()
+	if len(s.vals) > 1 {
+		l := s.lesser()
+		sort.Stable(l)
+		ReverseInterface(l)
+		// Put runs of equal values back in their original order
+		for i, n := 0, l.Len(); i < n; {
+			j := i + 1
+			for j < n && !l.Less(i, j) && !l.Less(j, i) {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				l.Swap(a, b)
+			}
+			i = j
+		}
+	}
+	s.permute()
+}
+
+// Retrieves and sorts the values to sort by, without modifying s.Slice.
+// Afterwards, s.order() returns the order the elements should be put in.
+func (s *Sorter) sortValues() {
+	s.setup()
+	if len(s.vals) < 2 {
+		return
+	}
+	if s.Ordering == DescendingStableTies {
+		// Sorting stably in descending order keeps ties in the same
+		// order as sorting stably in ascending order does.
+		l := s.lesser()
+		if l.Len() < insertionSortThreshold {
+			insertionSort(l)
+		} else {
+			sort.Stable(l)
+		}
+		return
+	}
+	l := s.lesser()
+	switch {
+	case reverseSorted(l):
+		// Values sorted in the opposite order can just be reversed
+		ReverseInterface(l)
+	case l.Len() < insertionSortThreshold:
+		insertionSort(l)
+	default:
+		adaptiveSort(l)
+	}
+}
+
+// Slices shorter than this are sorted using insertion sort, which does fewer
+// comparisons and swaps than sort.Sort and sort.Stable for very few elements.
+var insertionSortThreshold = 12
+
+// Sorts data stably using insertion sort.
+func insertionSort(data sort.Interface) {
+	for i := 1; i < data.Len(); i++ {
+		for j := i; j > 0 && data.Less(j, j-1); j-- {
+			data.Swap(j, j-1)
+		}
+	}
+}
+
+// Sorts data quickly if it is nearly sorted, e.g. a large slice of pointers
+// which was sorted before a few of its elements changed. Insertion sort does
+// one swap per pair of values that are out of order, and only a comparison
+// for each value that isn't, which is less work than sort.Sort does when
+// there are only a few; once it has done as many swaps as there are values,
+// the data isn't nearly sorted, and the rest is left to sort.Sort. (Since a
+// Sorter only swaps the values, this doesn't change how many elements of the
+// slice are written by permute: only the ones that move are, either way.)
+func adaptiveSort(data sort.Interface) {
+	n := data.Len()
+	budget := n
+	for i := 1; i < n; i++ {
+		for j := i; j > 0 && data.Less(j, j-1); j-- {
+			if budget == 0 {
+				sort.Sort(data)
+				return
+			}
+			data.Swap(j, j-1)
+			budget--
+		}
+	}
+}
+
+// Reports whether every element of data sorts strictly before the one
+// preceding it, i.e. whether data is sorted in the opposite order with no
+// equal elements.
+func reverseSorted(data sort.Interface) bool {
+	for i := data.Len() - 1; i > 0; i-- {
+		if !data.Less(i, i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// Retrieves the values to sort by, and prepares s for sorting.
+func (s *Sorter) setup() {
 	if s.Getter == nil {
 		s.Getter = SimpleGetter()
 	}
 	s.itemType = s.Slice.Index(0).Type()
-	s.vals = s.Getter(s.Slice)
-	one := s.vals[0]
+	if s.SkipErrors {
+		s.getSafe()
+	} else {
+		s.vals = s.Getter(s.Slice)
+		if len(s.vals) == 0 {
+			panic(errNoValues)
+		}
+		s.perm = identity(len(s.vals))
+		s.skipped = nil
+	}
+	if s.Normalize != nil {
+		s.normalize()
+	}
+}
+
+// The panic value used when a Getter returns no values for a slice that isn't
+// empty, e.g. because it extracts them from an empty nested slice.
+const errNoValues = "getter returned no values for non-empty slice"
+
+// Replaces the string values in s.vals with their normalized forms. s.vals is
+// copied first since it may belong to the Getter.
+func (s *Sorter) normalize() {
+	vals := valueSlice(len(s.vals))
+	for i, v := range s.vals {
+		if v.Kind() == reflect.String {
+			v = reflect.ValueOf(s.Normalize(v.String())).Convert(v.Type())
+		}
+		vals[i] = v
+	}
+	s.vals = vals
+}
+
+// Removes leading and trailing whitespace from str, and replaces each run of
+// whitespace within it with a single space. For use as a Sorter's Normalize
+// function, so that e.g. " bob", "bob " and "bob" compare equal, without
+// modifying the values being sorted.
+func CollapseSpace(str string) string {
+	return strings.Join(strings.Fields(str), " ")
+}
+
+// Returns the number of elements that were moved to the end of the slice
+// during the last call to Sort because their values couldn't be retrieved.
+// Always 0 unless s.SkipErrors is true.
+func (s *Sorter) Skipped() int {
+	return len(s.skipped)
+}
+
+// Populates s.vals by calling s.Getter on one element at a time, recovering
+// from any panics. Elements that cause a panic, or for which the Getter
+// returns a value of a different type than the first non-nil one, are
+// recorded in s.skipped.
+func (s *Sorter) getSafe() {
+	l := s.Slice.Len()
+	s.vals = valueSlice(0)
+	s.perm = make([]int, 0, l)
+	s.skipped = nil
+	var t reflect.Type
+	for i := 0; i < l; i++ {
+		v, ok := s.getOne(i)
+		if ok && v.IsValid() {
+			if t == nil {
+				t = v.Type()
+			} else if v.Type() != t {
+				ok = false
+			}
+		}
+		if !ok {
+			s.skipped = append(s.skipped, i)
+			continue
+		}
+		s.vals = append(s.vals, v)
+		s.perm = append(s.perm, i)
+	}
+}
+
+func (s *Sorter) getOne(i int) (v reflect.Value, ok bool) {
+	defer func() {
+		if x := recover(); x != nil {
+			ok = false
+		}
+	}()
+	vals := s.Getter(s.Slice.Slice(i, i+1))
+	if len(vals) != 1 {
+		return
+	}
+	return vals[0], true
+}
+
+// Returns the index of the first of s.vals which isn't nil.
+func (s *Sorter) first() (int, bool) {
+	for i, v := range s.vals {
+		if !isNil(v) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Returns the index in s.Slice of the element s.vals[i] was retrieved from.
+func (s *Sorter) index(i int) int {
+	if i < len(s.perm) {
+		return s.perm[i]
+	}
+	return i
+}
+
+// Returns a panic message describing s.vals[i], which can't be sorted.
+func (s *Sorter) unsortable(i int) string {
+	v := s.vals[i]
+	return fmt.Sprintf("Cannot sort by type %v: value %s at index %d", v.Type(), describe(v), s.index(i))
+}
+
+// Returns a sort.Interface for s.vals in the order specified by s.Ordering,
+// or nil if all the values are nil.
+func (s *Sorter) typed() sort.Interface {
+	first, ok := s.first()
+	if !ok {
+		// Only nils; there is nothing to compare
+		return nil
+	}
+	one := s.vals[first]
 	s.valType = one.Type()
 	s.valKind = one.Kind()
+	if o := s.Ordering.base(); (o == CaseInsensitiveAscending || o == CaseInsensitiveDescending) && !textual(s.valType) {
+		panic(fmt.Sprintf("Invalid ordering %v for type %v: case-insensitive orderings only apply to strings, []byte and []rune", s.Ordering, s.valType))
+	}
+	if s.Ordering == ModularAscending || s.Ordering == ModularDescending {
+		return s.modular()
+	}
+	if cmp := registered(s.valType); cmp != nil {
+		switch s.Ordering.base() {
+		default:
+			panic(fmt.Sprintf("Invalid ordering %v for %v", s.Ordering, s.valType))
+		case Ascending:
+			return registeredAscending{s, cmp}
+		case Descending:
+			return registeredDescending{s, cmp}
+		}
+	}
+	if s.mixedIntegers() {
+		switch s.Ordering.base() {
+		default:
+			panic(fmt.Sprintf("Invalid ordering %v for integers", s.Ordering))
+		case Ascending:
+			return mixedAscending{s}
+		case Descending:
+			return mixedDescending{s}
+		}
+	}
 	switch s.valKind {
 	// If the value isn't a standard kind, find a known type to sort by
 	default:
 		switch s.valType {
 		default:
-			panic(fmt.Sprintf("Cannot sort by type %v", s.valType))
+			panic(s.unsortable(first))
 		case t_time:
-			switch s.Ordering {
+			switch s.Ordering.base() {
 			default:
 				panic(fmt.Sprintf("Invalid ordering %v for time.Time", s.Ordering))
 			case Ascending:
-				sort.Sort(timeAscending{s})
+				return timeAscending{s}
+			case Descending:
+				return timeDescending{s}
+			}
+		case t_url:
+			switch s.Ordering.base() {
+			default:
+				panic(fmt.Sprintf("Invalid ordering %v for url.URL", s.Ordering))
+			case Ascending:
+				return urlAscending{s}
 			case Descending:
-				sort.Sort(timeDescending{s})
+				return urlDescending{s}
 			}
 		}
 	// Strings
 	case reflect.String:
-		switch s.Ordering {
+		switch s.Ordering.base() {
 		default:
 			panic(fmt.Sprintf("Invalid ordering %v for strings", s.Ordering))
 		case Ascending:
-			sort.Sort(stringAscending{s})
+			return stringAscending{s}
 		case Descending:
-			sort.Sort(stringDescending{s})
+			return stringDescending{s}
 		case CaseInsensitiveAscending:
-			sort.Sort(stringInsensitiveAscending{s})
+			return stringInsensitiveAscending{s}
 		case CaseInsensitiveDescending:
-			sort.Sort(stringInsensitiveDescending{s})
+			return stringInsensitiveDescending{s}
+		}
+	// Slices, compared element by element
+	case reflect.Slice:
+		switch s.valType.Elem().Kind() {
+		default:
+			if !comparableKind(s.valType.Elem().Kind()) {
+				panic(s.unsortable(first))
+			}
+			switch s.Ordering.base() {
+			default:
+				panic(fmt.Sprintf("Invalid ordering %v for slices", s.Ordering))
+			case Ascending:
+				return slicesAscending{s}
+			case Descending:
+				return slicesDescending{s}
+			}
+		case reflect.Uint8:
+			switch s.Ordering.base() {
+			default:
+				panic(fmt.Sprintf("Invalid ordering %v for byte slices", s.Ordering))
+			case Ascending:
+				return bytesAscending{s}
+			case Descending:
+				return bytesDescending{s}
+			case CaseInsensitiveAscending:
+				return bytesInsensitiveAscending{s}
+			case CaseInsensitiveDescending:
+				return bytesInsensitiveDescending{s}
+			}
+		case reflect.Int32:
+			switch s.Ordering.base() {
+			default:
+				panic(fmt.Sprintf("Invalid ordering %v for rune slices", s.Ordering))
+			case Ascending:
+				return runesAscending{s}
+			case Descending:
+				return runesDescending{s}
+			case CaseInsensitiveAscending:
+				return runesInsensitiveAscending{s}
+			case CaseInsensitiveDescending:
+				return runesInsensitiveDescending{s}
+			}
 		}
 	// Booleans
 	case reflect.Bool:
-		switch s.Ordering {
+		switch s.Ordering.base() {
 		default:
 			panic(fmt.Sprintf("Invalid ordering %v for booleans", s.Ordering))
 		case Ascending:
-			sort.Sort(boolAscending{s})
+			return boolAscending{s}
 		case Descending:
-			sort.Sort(boolDescending{s})
+			return boolDescending{s}
 		}
 	// Ints
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		switch s.Ordering {
+		switch s.Ordering.base() {
 		default:
 			panic(fmt.Sprintf("Invalid ordering %v for ints", s.Ordering))
 		case Ascending:
-			sort.Sort(intAscending{s})
+			return intAscending{s}
 		case Descending:
-			sort.Sort(intDescending{s})
+			return intDescending{s}
 		}
 	// Uints
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		switch s.Ordering {
+		switch s.Ordering.base() {
 		default:
 			panic(fmt.Sprintf("Invalid ordering %v for uints", s.Ordering))
 		case Ascending:
-			sort.Sort(uintAscending{s})
+			return uintAscending{s}
 		case Descending:
-			sort.Sort(uintDescending{s})
+			return uintDescending{s}
 		}
 	// Floats
 	case reflect.Float32, reflect.Float64:
-		switch s.Ordering {
+		switch s.Ordering.base() {
 		default:
 			panic(fmt.Sprintf("Invalid ordering %v for floats", s.Ordering))
 		case Ascending:
-			sort.Sort(floatAscending{s})
+			return floatAscending{s}
 		case Descending:
-			sort.Sort(floatDescending{s})
+			return floatDescending{s}
 		}
 	}
 }
 
-// Returns the length of the slice being sorted.
+// Reports whether values of type t can be compared case-insensitively.
+func textual(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Slice:
+		k := t.Elem().Kind()
+		return k == reflect.Uint8 || k == reflect.Int32
+	}
+	return false
+}
+
+// Returns a sort.Interface for the modular orderings.
+func (s *Sorter) modular() sort.Interface {
+	if !isInt(s.valKind) && !isUint(s.valKind) && s.valKind != reflect.Float32 && s.valKind != reflect.Float64 {
+		panic(fmt.Sprintf("Invalid ordering %v for type %v: modular orderings only apply to numbers", s.Ordering, s.valType))
+	}
+	if !(s.Modulus > 0) {
+		panic(fmt.Sprintf("Invalid modulus %v for ordering %v", s.Modulus, s.Ordering))
+	}
+	if s.Ordering == ModularDescending {
+		return modularDescending{s}
+	}
+	return modularAscending{s}
+}
+
+// Returns s.vals[i] as a float64 in the range [0, s.Modulus), relative to
+// s.Origin.
+func (s *Sorter) wrapped(i int) float64 {
+	var f float64
+	switch v := s.vals[i]; {
+	case isInt(v.Kind()):
+		f = float64(v.Int())
+	case isUint(v.Kind()):
+		f = float64(v.Uint())
+	default:
+		f = v.Float()
+	}
+	f = math.Mod(f-s.Origin, s.Modulus)
+	if f < 0 {
+		f += s.Modulus
+	}
+	return f
+}
+
+// Returns the number of values being sorted.
 func (s *Sorter) Len() int {
 	return len(s.vals)
 }
 
-// Swaps two indices in the slice being sorted.
+// Swaps the values at indices i and j, along with the indices in s.Slice of
+// the elements they were retrieved from. Len, Less and Swap operate on the
+// values retrieved by s.Getter, not on s.Slice: Sort retrieves the values,
+// sorts them, and only then moves the elements of s.Slice into the same
+// order, so that the values don't need to refer to the elements (they don't
+// when e.g. sorting a slice of pointers by a field, or skipping elements),
+// and each element is moved at most once rather than on every swap.
 func (s *Sorter) Swap(i, j int) {
-	x := s.Slice.Index(i)
-	y := s.Slice.Index(j)
-	tmp := reflect.New(s.itemType).Elem()
-	tmp.Set(x)
-	x.Set(y)
-	y.Set(tmp)
+	s.vals[i], s.vals[j] = s.vals[j], s.vals[i]
+	s.perm[i], s.perm[j] = s.perm[j], s.perm[i]
+}
+
+// Rearranges the elements of s.Slice according to s.perm, followed by any
+// skipped elements. Since the values retrieved by the Getter may or may not
+// refer to the elements of the slice (e.g. when sorting a slice of pointers),
+// the slice itself is only modified once all the comparisons are done. The
+// permutation is applied in place, one cycle at a time, so only the elements
+// that move are written.
+func (s *Sorter) permute() {
+	order := s.order()
+	done := make([]bool, len(order))
+	var tmp reflect.Value
+	for i := range order {
+		if done[i] || order[i] == i {
+			continue
+		}
+		if !tmp.IsValid() {
+			tmp = reflect.New(s.itemType).Elem()
+		}
+		tmp.Set(s.Slice.Index(i))
+		for j := i; ; {
+			done[j] = true
+			k := order[j]
+			if k == i {
+				s.Slice.Index(j).Set(tmp)
+				break
+			}
+			s.Slice.Index(j).Set(s.Slice.Index(k))
+			j = k
+		}
+	}
+}
+
+// Returns the original indices of the elements of s.Slice in sorted order.
+func (s *Sorter) order() []int {
+	return append(s.perm, s.skipped...)
+}
+
+func identity(l int) []int {
+	perm := make([]int, l, l)
+	for i := range perm {
+		perm[i] = i
+	}
+	return perm
 }
 
 // *cough* typedef *cough*
@@ -158,16 +715,32 @@ type stringAscending struct{ *Sorter }
 type stringDescending struct{ *Sorter }
 type stringInsensitiveAscending struct{ *Sorter }
 type stringInsensitiveDescending struct{ *Sorter }
+type bytesAscending struct{ *Sorter }
+type bytesDescending struct{ *Sorter }
+type bytesInsensitiveAscending struct{ *Sorter }
+type bytesInsensitiveDescending struct{ *Sorter }
+type runesAscending struct{ *Sorter }
+type runesDescending struct{ *Sorter }
+type runesInsensitiveAscending struct{ *Sorter }
+type runesInsensitiveDescending struct{ *Sorter }
+type slicesAscending struct{ *Sorter }
+type slicesDescending struct{ *Sorter }
 type boolAscending struct{ *Sorter }
 type boolDescending struct{ *Sorter }
 type intAscending struct{ *Sorter }
 type intDescending struct{ *Sorter }
 type uintAscending struct{ *Sorter }
 type uintDescending struct{ *Sorter }
+type mixedAscending struct{ *Sorter }
+type mixedDescending struct{ *Sorter }
 type floatAscending struct{ *Sorter }
 type floatDescending struct{ *Sorter }
 type timeAscending struct{ *Sorter }
 type timeDescending struct{ *Sorter }
+type urlAscending struct{ *Sorter }
+type urlDescending struct{ *Sorter }
+type modularAscending struct{ *Sorter }
+type modularDescending struct{ *Sorter }
 type reverser struct{ *Sorter }
 
 func (s stringAscending) Less(i, j int) bool {
@@ -179,11 +752,210 @@ func (s stringDescending) Less(i, j int) bool {
 }
 
 func (s stringInsensitiveAscending) Less(i, j int) bool {
-	return strings.ToLower(s.Sorter.vals[i].String()) < strings.ToLower(s.Sorter.vals[j].String())
+	return compareFoldString(s.Sorter.vals[i].String(), s.Sorter.vals[j].String()) < 0
 }
 
 func (s stringInsensitiveDescending) Less(i, j int) bool {
-	return strings.ToLower(s.Sorter.vals[i].String()) > strings.ToLower(s.Sorter.vals[j].String())
+	return compareFoldString(s.Sorter.vals[i].String(), s.Sorter.vals[j].String()) > 0
+}
+
+// Compares a and b like strings.Compare(strings.ToLower(a),
+// strings.ToLower(b)), but without allocating. ASCII characters are lowered
+// without decoding them.
+func compareFoldString(a, b string) int {
+	for a != "" && b != "" {
+		var x, y rune
+		if c := a[0]; c < utf8.RuneSelf {
+			x, a = rune(lowerASCII(c)), a[1:]
+		} else {
+			r, n := utf8.DecodeRuneInString(a)
+			x, a = unicode.ToLower(r), a[n:]
+		}
+		if c := b[0]; c < utf8.RuneSelf {
+			y, b = rune(lowerASCII(c)), b[1:]
+		} else {
+			r, n := utf8.DecodeRuneInString(b)
+			y, b = unicode.ToLower(r), b[n:]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case b != "":
+		return -1
+	case a != "":
+		return 1
+	}
+	return 0
+}
+
+func (s bytesAscending) Less(i, j int) bool {
+	return bytes.Compare(s.Sorter.vals[i].Bytes(), s.Sorter.vals[j].Bytes()) < 0
+}
+
+func (s bytesDescending) Less(i, j int) bool {
+	return bytes.Compare(s.Sorter.vals[i].Bytes(), s.Sorter.vals[j].Bytes()) > 0
+}
+
+func (s bytesInsensitiveAscending) Less(i, j int) bool {
+	return compareFoldASCII(s.Sorter.vals[i].Bytes(), s.Sorter.vals[j].Bytes()) < 0
+}
+
+func (s bytesInsensitiveDescending) Less(i, j int) bool {
+	return compareFoldASCII(s.Sorter.vals[i].Bytes(), s.Sorter.vals[j].Bytes()) > 0
+}
+
+// Compares a and b like bytes.Compare, but with ASCII letters folded to lower
+// case. Unlike bytes.Compare(bytes.ToLower(a), bytes.ToLower(b)), this doesn't
+// allocate.
+func compareFoldASCII(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := lowerASCII(a[i]), lowerASCII(b[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func (s runesAscending) Less(i, j int) bool {
+	return compareRunes(s.Sorter.vals[i], s.Sorter.vals[j], false) < 0
+}
+
+func (s runesDescending) Less(i, j int) bool {
+	return compareRunes(s.Sorter.vals[i], s.Sorter.vals[j], false) > 0
+}
+
+func (s runesInsensitiveAscending) Less(i, j int) bool {
+	return compareRunes(s.Sorter.vals[i], s.Sorter.vals[j], true) < 0
+}
+
+func (s runesInsensitiveDescending) Less(i, j int) bool {
+	return compareRunes(s.Sorter.vals[i], s.Sorter.vals[j], true) > 0
+}
+
+// Compares two rune slices a and b by their code points, optionally folding
+// each rune to lower case first.
+func compareRunes(a, b reflect.Value, fold bool) int {
+	la, lb := a.Len(), b.Len()
+	for i := 0; i < la && i < lb; i++ {
+		x, y := rune(a.Index(i).Int()), rune(b.Index(i).Int())
+		if fold {
+			x, y = unicode.ToLower(x), unicode.ToLower(y)
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case la < lb:
+		return -1
+	case la > lb:
+		return 1
+	}
+	return 0
+}
+
+func (s slicesAscending) Less(i, j int) bool {
+	return compareSlices(s.Sorter.vals[i], s.Sorter.vals[j]) < 0
+}
+
+func (s slicesDescending) Less(i, j int) bool {
+	return compareSlices(s.Sorter.vals[i], s.Sorter.vals[j]) > 0
+}
+
+// Reports whether compareScalars can compare values of kind k.
+func comparableKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// Compares two ints, uints, floats or strings of the same kind. NaNs are
+// considered less than all other floats.
+func compareScalars(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, y := a.Int(), b.Int()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, y := a.Uint(), b.Uint()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case reflect.Float32, reflect.Float64:
+		x, y := a.Float(), b.Float()
+		switch {
+		case x < y || math.IsNaN(x) && !math.IsNaN(y):
+			return -1
+		case x > y || !math.IsNaN(x) && math.IsNaN(y):
+			return 1
+		}
+	case reflect.String:
+		x, y := a.String(), b.String()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	default:
+		panic(fmt.Sprintf("Cannot compare values of type %v", a.Type()))
+	}
+	return 0
+}
+
+// Compares two slices element by element. If one slice is a prefix of the
+// other, the shorter slice is less.
+func compareSlices(a, b reflect.Value) int {
+	la, lb := a.Len(), b.Len()
+	for i := 0; i < la && i < lb; i++ {
+		if c := compareScalars(a.Index(i), b.Index(i)); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case la < lb:
+		return -1
+	case la > lb:
+		return 1
+	}
+	return 0
 }
 
 func (s boolAscending) Less(i, j int) bool {
@@ -198,6 +970,87 @@ func (s intDescending) Less(i, j int) bool  { return s.Sorter.vals[i].Int() > s.
 func (s uintAscending) Less(i, j int) bool  { return s.Sorter.vals[i].Uint() < s.Sorter.vals[j].Uint() }
 func (s uintDescending) Less(i, j int) bool { return s.Sorter.vals[i].Uint() > s.Sorter.vals[j].Uint() }
 
+func (s mixedAscending) Less(i, j int) bool {
+	return compareIntegers(s.Sorter.vals[i], s.Sorter.vals[j]) < 0
+}
+
+func (s mixedDescending) Less(i, j int) bool {
+	return compareIntegers(s.Sorter.vals[i], s.Sorter.vals[j]) > 0
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uint64
+}
+
+// Reports whether s.vals contains both signed and unsigned integers, e.g.
+// when retrieved by a Getter from different struct types.
+func (s *Sorter) mixedIntegers() bool {
+	if !isInt(s.valKind) && !isUint(s.valKind) {
+		return false
+	}
+	for _, v := range s.vals {
+		if isNil(v) {
+			continue
+		}
+		if k := v.Kind(); isInt(k) != isInt(s.valKind) && (isInt(k) || isUint(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether v can be compared with values of type s.valType, i.e.
+// whether it has the same type, or it's a number, string or bool like them,
+// or a slice of the same kind of elements.
+func (s *Sorter) compatible(v reflect.Value) bool {
+	return v.Type() == s.valType || sameClass(v.Type(), s.valType)
+}
+
+func sameClass(a, b reflect.Type) bool {
+	x, y := a.Kind(), b.Kind()
+	switch {
+	case (isInt(x) || isUint(x)) && (isInt(y) || isUint(y)):
+		return true
+	case (x == reflect.Float32 || x == reflect.Float64) && (y == reflect.Float32 || y == reflect.Float64):
+		return true
+	case x == reflect.String || x == reflect.Bool:
+		return x == y
+	case x == reflect.Slice && y == reflect.Slice:
+		return a.Elem() == b.Elem() || sameClass(a.Elem(), b.Elem())
+	}
+	return false
+}
+
+// Compares two integers, each of which may be signed or unsigned, without
+// overflowing, e.g. when comparing a negative int64 with a uint64 larger than
+// the largest int64.
+func compareIntegers(a, b reflect.Value) int {
+	if isInt(a.Kind()) && isInt(b.Kind()) {
+		return compareScalars(a, b)
+	}
+	if isUint(a.Kind()) && isUint(b.Kind()) {
+		return compareScalars(a, b)
+	}
+	if isInt(a.Kind()) {
+		if a.Int() < 0 {
+			return -1
+		}
+		x, y := uint64(a.Int()), b.Uint()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return -compareIntegers(b, a)
+}
+
 func (s floatAscending) Less(i, j int) bool {
 	a := s.Sorter.vals[i].Float()
 	b := s.Sorter.vals[j].Float()
@@ -210,27 +1063,94 @@ func (s floatDescending) Less(i, j int) bool {
 	return a > b || !math.IsNaN(a) && math.IsNaN(b)
 }
 
+func (s modularAscending) Less(i, j int) bool {
+	return s.Sorter.wrapped(i) < s.Sorter.wrapped(j)
+}
+
+func (s modularDescending) Less(i, j int) bool {
+	return s.Sorter.wrapped(i) > s.Sorter.wrapped(j)
+}
+
 func (s timeAscending) Less(i, j int) bool {
-	return s.Sorter.vals[i].Interface().(time.Time).Before(s.Sorter.vals[j].Interface().(time.Time))
+	return s.Sorter.time(i).Before(s.Sorter.time(j))
 }
 
 func (s timeDescending) Less(i, j int) bool {
-	return s.Sorter.vals[i].Interface().(time.Time).After(s.Sorter.vals[j].Interface().(time.Time))
+	return s.Sorter.time(i).After(s.Sorter.time(j))
+}
+
+// Returns s.vals[i] as a time.Time, truncated to s.TimeResolution.
+func (s *Sorter) time(i int) time.Time {
+	t := s.vals[i].Interface().(time.Time)
+	if s.TimeResolution > 0 {
+		t = t.Truncate(s.TimeResolution)
+	}
+	return t
+}
+
+func (s urlAscending) Less(i, j int) bool {
+	return s.Sorter.compareURLs(i, j) < 0
+}
+
+func (s urlDescending) Less(i, j int) bool {
+	return s.Sorter.compareURLs(i, j) > 0
+}
+
+// Compares s.vals[i] and s.vals[j] as url.URLs by their string forms, or by
+// their hosts and paths first if s.URLByHost is true.
+func (s *Sorter) compareURLs(i, j int) int {
+	a, b := s.vals[i].Interface().(url.URL), s.vals[j].Interface().(url.URL)
+	if s.URLByHost {
+		if c := strings.Compare(a.Host, b.Host); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a.String(), b.String())
 }
 
 func (s reverser) Len() int {
 	return s.Sorter.Slice.Len()
 }
 
+func (s reverser) Swap(i, j int) {
+	x := s.Sorter.Slice.Index(i)
+	y := s.Sorter.Slice.Index(j)
+	tmp := reflect.New(s.Sorter.itemType).Elem()
+	tmp.Set(x)
+	x.Set(y)
+	y.Set(tmp)
+}
+
 // Unused--only to satisfy sort.Interface
 func (s reverser) Less(i, j int) bool {
 	return i < j
 }
 
 // Returns a Sorter for a slice which will sort according to the
-// items retrieved by getter, in the given ordering.
+// items retrieved by getter, in the given ordering. slice may also be a
+// pointer to a slice or array, in which case the slice or array it points
+// to is sorted. A nil slice or pointer is treated like an empty slice.
 func New(slice interface{}, getter Getter, ordering Ordering) *Sorter {
 	v := reflect.ValueOf(slice)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			// Like a nil slice, a nil pointer has nothing to sort
+			t := v.Type().Elem()
+			if t.Kind() == reflect.Array {
+				t = reflect.SliceOf(t.Elem())
+			}
+			v = reflect.Zero(t)
+		} else {
+			v = v.Elem()
+		}
+	}
+	if !v.IsValid() {
+		// slice is nil itself
+		v = reflect.ValueOf([]interface{}(nil))
+	}
 	return &Sorter{
 		Slice:    v,
 		Getter:   getter,
@@ -248,6 +1168,240 @@ func Sort(slice interface{}, getter Getter, ordering Ordering) {
 	New(slice, getter, ordering).Sort()
 }
 
+// Like Sort, but returns a sorted copy of the slice, leaving the slice itself
+// untouched. The copy has the same type as the slice, e.g. an []Item for an
+// []Item, or a slice of the element type for an array or a pointer to one.
+func SortedCopy(slice interface{}, getter Getter, ordering Ordering) interface{} {
+	v := New(slice, getter, ordering).Slice
+	t := v.Type()
+	if t.Kind() == reflect.Array {
+		t = reflect.SliceOf(t.Elem())
+	}
+	c := reflect.MakeSlice(t, v.Len(), v.Len())
+	reflect.Copy(c, v)
+	New(c.Interface(), getter, ordering).Sort()
+	return c.Interface()
+}
+
+// Returns the indices of the elements of a slice in the order they would be
+// in if the slice was sorted using a Getter in the order specified by
+// Ordering, without modifying the slice. E.g. the first index is that of the
+// element that would be first.
+func ArgSort(slice interface{}, getter Getter, ordering Ordering) []int {
+	s := New(slice, getter, ordering)
+	if s.Slice.Len() < 2 {
+		return identity(s.Slice.Len())
+	}
+	s.sortValues()
+	return s.order()
+}
+
+// Sort a slice using a Getter in the order specified by Ordering, returning
+// the permutation that was applied, where perm[i] is the original index of
+// the element that is now at index i, and its inverse, where inverse[i] is
+// the new index of the element that was at index i. The original order can
+// be restored by moving each element at index inverse[i] back to index i.
+func SortWithPermutation(slice interface{}, getter Getter, ordering Ordering) (perm []int, inverse []int) {
+	s := New(slice, getter, ordering)
+	if s.Slice.Len() < 2 {
+		perm = identity(s.Slice.Len())
+		return perm, identity(len(perm))
+	}
+	s.sortValues()
+	perm = append([]int(nil), s.order()...)
+	s.permute()
+	inverse = make([]int, len(perm))
+	for i, v := range perm {
+		inverse[v] = i
+	}
+	return perm, inverse
+}
+
+// Sort a slice using a Getter in the order specified by Ordering, calling
+// onMove(from, to) for each element that was moved, in order of the new
+// indices, where from is the element's original index and to is its new one.
+// Elements that stayed in place aren't reported.
+func SortWithMoves(slice interface{}, getter Getter, ordering Ordering, onMove func(from, to int)) {
+	perm, _ := SortWithPermutation(slice, getter, ordering)
+	for to, from := range perm {
+		if from != to {
+			onMove(from, to)
+		}
+	}
+}
+
+// Sort the elements with indices lo through hi-1 of a slice using a Getter in
+// the order specified by Ordering, leaving the other elements untouched. A
+// runtime panic will occur if lo and hi aren't valid bounds for the slice.
+func SortRange(slice interface{}, getter Getter, ordering Ordering, lo, hi int) {
+	s := New(slice, getter, ordering)
+	if lo < 0 || hi < lo || hi > s.Slice.Len() {
+		panic(fmt.Sprintf("Invalid range [%d:%d] for slice of length %d", lo, hi, s.Slice.Len()))
+	}
+	s.Slice = s.Slice.Slice(lo, hi)
+	s.Sort()
+}
+
+// Sort a slice using a Getter in the order specified by Ordering, moving any
+// elements for which getter panics, e.g. nil pointers in a slice of pointers
+// to structs, to the end of the slice instead of panicking. Nil values, e.g.
+// nil pointer fields, are sorted like nils rather than skipped. Returns the
+// number of elements that were skipped.
+func SortSkipErrors(slice interface{}, getter Getter, ordering Ordering) int {
+	s := New(slice, getter, ordering)
+	s.SkipErrors = true
+	s.Sort()
+	return s.Skipped()
+}
+
+// Returns a less function for the elements of slice, comparing them by their
+// fields with name in the given ordering, for use with sort.Slice or
+// sort.SliceStable, e.g.
+//
+//	sort.Slice(items, sortutil.LessForField(items, "Name", sortutil.Ascending))
+//
+// The fields are retrieved each time the function is called, so it remains
+// valid as the elements of the slice are swapped. The function must not be
+// called concurrently. A runtime panic will occur if the fields can't be
+// compared.
+func LessForField(slice interface{}, name string, ordering Ordering) func(i, j int) bool {
+	s := New(slice, FieldGetter(name), ordering)
+	key := func(i int) reflect.Value {
+		return indirect(indirect(s.Slice.Index(i)).FieldByName(name))
+	}
+	less := s.lessFunc(s.Getter(s.Slice))
+	return func(i, j int) bool {
+		return less(key(i), key(j))
+	}
+}
+
+// Sort a slice by the values key returns for the element at each index, in
+// the given ordering, e.g. to sort by a value computed by a closure. key is
+// called once for each index before the slice is modified. Pointers are
+// dereferenced, and nil values are placed like in a Sorter with the default
+// Policy. A runtime panic will occur if the values can't be compared.
+func SortByKeyFunc(slice interface{}, key func(i int) interface{}, ordering Ordering) {
+	getter := func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			vals[i] = indirect(reflect.ValueOf(key(i)))
+		}
+		return vals
+	}
+	New(slice, getter, ordering).Sort()
+}
+
+// A Collator compares strings according to the rules of a language, e.g. a
+// *collate.Collator from golang.org/x/text/collate. Like bytes.Compare,
+// CompareString returns -1, 0 or 1.
+type Collator interface {
+	CompareString(a, b string) int
+}
+
+// Sort a slice in ascending order by a string field name using a Collator,
+// e.g. to sort names the way a given language does. c is only used to
+// compare the fields, so a single Collator can be reused for many sorts,
+// provided they don't happen concurrently.
+func AscByFieldCollated(slice interface{}, name string, c Collator) {
+	SortByLess(slice, FieldKey(name), func(a, b reflect.Value) bool {
+		return c.CompareString(a.String(), b.String()) < 0
+	})
+}
+
+// Sort a slice in descending order by a string field name using a Collator.
+func DescByFieldCollated(slice interface{}, name string, c Collator) {
+	SortByLess(slice, FieldKey(name), func(a, b reflect.Value) bool {
+		return c.CompareString(a.String(), b.String()) > 0
+	})
+}
+
+// Sort a slice in ascending order by a field name, comparing the fields using
+// cmp, which returns a negative number if a sorts before b, a positive number
+// if a sorts after b, and 0 if they are equal. This allows sorting by a field
+// of a type which isn't otherwise supported, e.g. a custom struct. Pointer
+// fields are dereferenced before they are passed to cmp.
+func AscByFieldCmp(slice interface{}, name string, cmp func(a, b reflect.Value) int) {
+	SortByLess(slice, FieldKey(name), func(a, b reflect.Value) bool {
+		return cmp(a, b) < 0
+	})
+}
+
+// Sort a slice in descending order by a field name, comparing the fields using
+// cmp. See AscByFieldCmp.
+func DescByFieldCmp(slice interface{}, name string, cmp func(a, b reflect.Value) int) {
+	SortByLess(slice, FieldKey(name), func(a, b reflect.Value) bool {
+		return cmp(a, b) > 0
+	})
+}
+
+// Sort a slice by the values returned by key, in the order decided by less,
+// which reports whether a should sort before b. Unlike with sort.Slice and a
+// less function that retrieves the values itself, key is called exactly once
+// for each element, no matter how many times it is compared, so this is
+// preferable when retrieving the values is expensive, e.g. when it involves
+// computing a score.
+func SortByLess(slice interface{}, key KeyFunc, less func(a, b reflect.Value) bool) {
+	s := New(slice, key.Getter(), Ascending)
+	if s.Slice.Len() < 2 {
+		return
+	}
+	s.setup()
+	sort.Sort(customLess{s, less})
+	s.permute()
+}
+
+// A sort.Interface comparing s.vals using a custom less function.
+type customLess struct {
+	*Sorter
+	less func(a, b reflect.Value) bool
+}
+
+func (c customLess) Less(i, j int) bool {
+	return c.less(c.Sorter.vals[i], c.Sorter.vals[j])
+}
+
+// Compares a and b, which must be of the same type, returning -1 if a sorts
+// before b in the given ordering, 1 if a sorts after b, and 0 if they are
+// equal. Pointers are dereferenced, and nil values sort like they would in a
+// Sorter with the default Policy. This lets e.g. a container/heap or a tree
+// use the same comparison rules as the sort functions. A runtime panic will
+// occur if the values can't be compared.
+func Compare(a, b interface{}, ordering Ordering) int {
+	x, y := indirect(reflect.ValueOf(a)), indirect(reflect.ValueOf(b))
+	less := (&Sorter{Ordering: ordering}).lessFunc([]reflect.Value{x, y})
+	switch {
+	case less(x, y):
+		return -1
+	case less(y, x):
+		return 1
+	}
+	return 0
+}
+
+// Returns a function which reports whether a should sort before b in the
+// order specified by s.Ordering, placing values according to s.Policy. The
+// type of the values being compared is determined using vals, or using the
+// first two values that are compared if vals are all nil (or empty). The
+// function must not be called concurrently, and s must not be used to sort
+// anything afterwards.
+func (s *Sorter) lessFunc(vals []reflect.Value) func(a, b reflect.Value) bool {
+	s.vals = vals
+	t := s.typed()
+	s.vals = valueSlice(2)
+	return func(a, b reflect.Value) bool {
+		pa, _ := s.Policy.place(a, s.Ordering)
+		pb, _ := s.Policy.place(b, s.Ordering)
+		if pa != 0 || pb != 0 {
+			return pa < pb
+		}
+		s.vals[0], s.vals[1] = a, b
+		if t == nil {
+			t = s.typed()
+		}
+		return t.Less(0, 1)
+	}
+}
+
 // Sort a slice in ascending order.
 func Asc(slice interface{}) {
 	New(slice, nil, Ascending).Sort()
@@ -290,6 +1444,125 @@ func CiDescByField(slice interface{}, name string) {
 	New(slice, FieldGetter(name), CaseInsensitiveDescending).Sort()
 }
 
+// Sort a slice in ascending order by a field name matched
+// case-insensitively. See FieldGetterCI.
+func AscByFieldCI(slice interface{}, name string) {
+	New(slice, FieldGetterCI(name), Ascending).Sort()
+}
+
+// Sort a slice in descending order by a field name matched
+// case-insensitively. See FieldGetterCI.
+func DescByFieldCI(slice interface{}, name string) {
+	New(slice, FieldGetterCI(name), Descending).Sort()
+}
+
+// Sort a slice stably in ascending order by a field name, then reverse it,
+// e.g. to present a list sorted by Name from last to first. Unlike DescByField
+// and a stable descending sort, elements whose fields are equal end up in the
+// reverse of their original relative order.
+func AscByFieldThenReverse(slice interface{}, name string) {
+	s := New(slice, FieldGetter(name), Ascending)
+	if s.Slice.Len() < 2 {
+		return
+	}
+	s.setup()
+	l := s.lesser()
+	sort.Stable(l)
+	ReverseInterface(l)
+	s.permute()
+}
+
+// Sort a slice of structs by how many of their exported fields aren't zero,
+// from the least to the most complete. See CompletenessGetter.
+func AscByCompleteness(slice interface{}) {
+	New(slice, CompletenessGetter(), Ascending).Sort()
+}
+
+// Sort a slice of structs by how many of their exported fields aren't zero,
+// from the most to the least complete. See CompletenessGetter.
+func DescByCompleteness(slice interface{}) {
+	New(slice, CompletenessGetter(), Descending).Sort()
+}
+
+// Sort a slice by a bool field name, with false before true. (Valid for bool
+// types.)
+func AscByBoolField(slice interface{}, name string) {
+	New(slice, FieldGetter(name), Ascending).Sort()
+}
+
+// Sort a slice by a bool field name, with true before false. (Valid for bool
+// types.)
+func DescByBoolField(slice interface{}, name string) {
+	New(slice, FieldGetter(name), Descending).Sort()
+}
+
+// Sort a slice by the result of calling pred on each element, with the
+// elements for which pred returns false before those for which it returns
+// true.
+func AscByPredicate(slice interface{}, pred func(reflect.Value) bool) {
+	New(slice, predicateKey(pred).Getter(), Ascending).Sort()
+}
+
+// Sort a slice by the result of calling pred on each element, with the
+// elements for which pred returns true before those for which it returns
+// false.
+func DescByPredicate(slice interface{}, pred func(reflect.Value) bool) {
+	New(slice, predicateKey(pred).Getter(), Descending).Sort()
+}
+
+func predicateKey(pred func(reflect.Value) bool) KeyFunc {
+	return func(elem reflect.Value) reflect.Value {
+		return reflect.ValueOf(pred(elem))
+	}
+}
+
+// Sort a slice in descending order by a field name, placing nils after all
+// other values, like ORDER BY name DESC NULLS LAST in SQL. Nils are nil
+// pointers, as well as database/sql null types like sql.NullInt64 whose Valid
+// field is false. Valid values of those types are compared by their
+// underlying values.
+func DescByFieldNullsLast(slice interface{}, name string) {
+	s := New(slice, nullableGetter(FieldGetter(name)), Descending)
+	s.Policy.Nils = Last
+	s.Sort()
+}
+
+// Sort a slice in ascending order by a field name, placing nils after all
+// other values, like ORDER BY name ASC NULLS LAST in SQL. See
+// DescByFieldNullsLast.
+func AscByFieldNullsLast(slice interface{}, name string) {
+	s := New(slice, nullableGetter(FieldGetter(name)), Ascending)
+	s.Policy.Nils = Last
+	s.Sort()
+}
+
+// Returns a Getter which replaces the values retrieved by getter that
+// implement driver.Valuer, e.g. sql.NullString, with their underlying values,
+// or with nil if they aren't valid.
+func nullableGetter(getter Getter) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := getter(s)
+		for i, v := range vals {
+			if !v.IsValid() || !v.Type().Implements(t_valuer) {
+				continue
+			}
+			dv, err := v.Interface().(driver.Valuer).Value()
+			if err != nil {
+				panic(fmt.Sprintf("Cannot get the value of %v at index %d: %v", v.Type(), i, err))
+			}
+			vals[i] = reflect.ValueOf(dv)
+		}
+		return vals
+	}
+}
+
+// Sort a slice by a field name in the order in which the fields' values
+// appear in order, e.g. []interface{}{"high", "medium", "low"}. Elements
+// whose fields have values that aren't in order are placed last.
+func AscByFieldWithOrder(slice interface{}, name string, order []interface{}) {
+	New(slice, RankGetter(FieldGetter(name), order), Ascending).Sort()
+}
+
 // Sort a slice in ascending order by a list of nested field indices, e.g. 1, 2,
 // 3 to sort by the third field from the struct in the second field of the struct
 // in the first field of each struct in the slice.
@@ -320,6 +1593,12 @@ func CiDescByFieldIndex(slice interface{}, index []int) {
 	New(slice, FieldByIndexGetter(index), CaseInsensitiveDescending).Sort()
 }
 
+// Sort a slice by a pre-resolved struct field, e.g. one returned by
+// reflect.Type.FieldByName, in the order specified by ordering.
+func SortByStructField(slice interface{}, field reflect.StructField, ordering Ordering) {
+	New(slice, StructFieldGetter(field), ordering).Sort()
+}
+
 // Sort a slice in ascending order by an index in a child slice.
 func AscByIndex(slice interface{}, index int) {
 	New(slice, IndexGetter(index), Ascending).Sort()
@@ -330,6 +1609,16 @@ func DescByIndex(slice interface{}, index int) {
 	New(slice, IndexGetter(index), Descending).Sort()
 }
 
+// Sort a slice of slices in ascending order by the sums of the child slices.
+func AscBySum(slice interface{}) {
+	New(slice, SumGetter(), Ascending).Sort()
+}
+
+// Sort a slice of slices in descending order by the sums of the child slices.
+func DescBySum(slice interface{}) {
+	New(slice, SumGetter(), Descending).Sort()
+}
+
 // Sort a slice in case-insensitive ascending order by an index in a child
 // slice. (Valid for string types.)
 func CiAscByIndex(slice interface{}, index int) {
@@ -342,8 +1631,20 @@ func CiDescByIndex(slice interface{}, index int) {
 	New(slice, IndexGetter(index), CaseInsensitiveDescending).Sort()
 }
 
-// Reverse a slice.
+// Reverse a slice. []int, []string and []float64 are reversed without using
+// reflection.
 func Reverse(slice interface{}) {
+	switch v := slice.(type) {
+	case []int:
+		ReverseInts(v)
+		return
+	case []string:
+		ReverseStrings(v)
+		return
+	case []float64:
+		ReverseFloats(v)
+		return
+	}
 	s := reverser{New(slice, nil, 0)}
 	if s.Len() < 2 {
 		return
@@ -366,3 +1667,30 @@ func SortReverseInterface(s sort.Interface) {
 	sort.Sort(s)
 	ReverseInterface(s)
 }
+
+// Reports whether data, which has been sorted, was sorted stably, i.e. it is
+// sorted, and elements that are equal according to data.Less are in their
+// original relative order. original(i) must return the index the element now
+// at index i had before sorting, e.g. one stored in the elements. This is
+// useful for checking that a sort.Interface implementation, or a sorting
+// function, keeps equal elements in order.
+func IsStableSort(data sort.Interface, original func(i int) int) bool {
+	for i := 1; i < data.Len(); i++ {
+		if data.Less(i, i-1) {
+			return false
+		}
+		if !data.Less(i-1, i) && original(i-1) > original(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sort a type using its existing sort.Interface with sort.Stable, then
+// reverse it. Unlike SortReverseInterface, the resulting order of elements
+// that are equal is predictable: they appear in the reverse of their
+// original order.
+func SortStableReverse(s sort.Interface) {
+	sort.Stable(s)
+	ReverseInterface(s)
+}
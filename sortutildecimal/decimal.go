@@ -0,0 +1,26 @@
+//go:build sortutildecimal
+
+// Package sortutildecimal registers decimal.Decimal from
+// github.com/shopspring/decimal with sortutil, so that slices can be sorted
+// by decimal values, and by fields of type decimal.Decimal or
+// *decimal.Decimal. Import it for its side effects, and build with the
+// sortutildecimal build tag:
+//
+//	import _ "github.com/patrickmn/sortutil/sortutildecimal"
+package sortutildecimal
+
+import (
+	"reflect"
+
+	"github.com/patrickmn/sortutil"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	sortutil.RegisterType(reflect.TypeOf(decimal.Decimal{}), Compare)
+}
+
+// Compares two reflect.Values holding decimal.Decimals using Decimal.Cmp.
+func Compare(a, b reflect.Value) int {
+	return a.Interface().(decimal.Decimal).Cmp(b.Interface().(decimal.Decimal))
+}
@@ -0,0 +1,64 @@
+//go:build sortutildecimal
+
+package sortutildecimal
+
+import (
+	"testing"
+
+	"github.com/patrickmn/sortutil"
+	"github.com/shopspring/decimal"
+)
+
+type Payment struct {
+	Id     int
+	Amount decimal.Decimal
+	Fee    *decimal.Decimal
+}
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func decPtr(s string) *decimal.Decimal {
+	d := dec(s)
+	return &d
+}
+
+func payments() []Payment {
+	return []Payment{
+		{1, dec("10.50"), decPtr("0.30")},
+		{2, dec("9.99"), nil},
+		{3, dec("100"), decPtr("0.03")},
+		{4, dec("10.5000001"), decPtr("1")},
+	}
+}
+
+func TestAscByDecimalField(t *testing.T) {
+	ps := payments()
+	sortutil.AscByField(ps, "Amount")
+	c := []int{2, 1, 4, 3}
+	for i, v := range ps {
+		if v.Id != c[i] {
+			t.Errorf("ps[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestDescByDecimalPointerField(t *testing.T) {
+	ps := payments()
+	sortutil.DescByField(ps, "Fee")
+	c := []int{4, 1, 3, 2}
+	for i, v := range ps {
+		if v.Id != c[i] {
+			t.Errorf("ps[%d].Id is not %d, but %d", i, c[i], v.Id)
+		}
+	}
+}
+
+func TestAscDecimals(t *testing.T) {
+	ds := []decimal.Decimal{dec("3"), dec("-1.5"), dec("2.25")}
+	sortutil.Asc(ds)
+	if !ds[0].Equal(dec("-1.5")) || !ds[1].Equal(dec("2.25")) || !ds[2].Equal(dec("3")) {
+		t.Errorf("Decimals are not sorted: %v", ds)
+	}
+}
@@ -0,0 +1,59 @@
+package sortutil
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Sort the rows of a table, e.g. one read using encoding/csv, whose first row
+// is a header, by the column with the given name in the header, keeping the
+// header row first. Rows that are too short to have the column are sorted as
+// if the column was empty. An error is returned if no column has the name.
+func SortTableByColumn(rows [][]string, column string, ordering Ordering) error {
+	return sortTable(rows, column, ordering, false)
+}
+
+// Like SortTableByColumn, but compares the values in the column as numbers.
+// Values that aren't numbers are placed like NaNs.
+func SortTableByColumnNumeric(rows [][]string, column string, ordering Ordering) error {
+	return sortTable(rows, column, ordering, true)
+}
+
+func sortTable(rows [][]string, column string, ordering Ordering, numeric bool) error {
+	if len(rows) == 0 {
+		return fmt.Errorf("Table has no header row")
+	}
+	col := -1
+	for i, v := range rows[0] {
+		if v == column {
+			col = i
+			break
+		}
+	}
+	if col == -1 {
+		return fmt.Errorf("Table has no column %q", column)
+	}
+	g := cellGetter(col)
+	if numeric {
+		g = NumericGetter(g)
+	}
+	New(rows[1:], g, ordering).Sort()
+	return nil
+}
+
+// Returns a Getter which gets the cell in column col of each row of a
+// [][]string, or an empty string if the row is too short.
+func cellGetter(col int) Getter {
+	return func(s reflect.Value) []reflect.Value {
+		vals := valueSlice(s.Len())
+		for i := range vals {
+			row := s.Index(i)
+			if col < row.Len() {
+				vals[i] = row.Index(col)
+			} else {
+				vals[i] = reflect.ValueOf("")
+			}
+		}
+		return vals
+	}
+}
@@ -0,0 +1,75 @@
+package sortutil
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Sort a []string in the given ordering. Unlike Asc, Desc, etc., this doesn't
+// use reflection, so it's as fast as sort.Strings. Only Ascending,
+// Descending, CaseInsensitiveAscending, CaseInsensitiveDescending and
+// DescendingStableTies are supported; a runtime panic will occur for the
+// other Orderings, e.g. NonZeroFirstAscending, which need a Sorter.
+func SortStrings(s []string, ordering Ordering) {
+	switch ordering {
+	case Ascending:
+		sort.Sort(sort.StringSlice(s))
+	case Descending:
+		sort.Sort(sort.Reverse(sort.StringSlice(s)))
+	case CaseInsensitiveAscending:
+		sort.Sort(stringsInsensitive(s))
+	case CaseInsensitiveDescending:
+		sort.Sort(sort.Reverse(stringsInsensitive(s)))
+	case DescendingStableTies:
+		sort.Stable(sort.Reverse(sort.StringSlice(s)))
+	default:
+		panic(fmt.Sprintf("Invalid ordering %v", ordering))
+	}
+}
+
+// Sort a []int in the given ordering. Unlike Asc, Desc, etc., this doesn't
+// use reflection, so it's as fast as sort.Ints. Only Ascending, Descending
+// and DescendingStableTies are supported; a runtime panic will occur for the
+// other Orderings, e.g. case-insensitive ones, or NonZeroFirstAscending and
+// ModularAscending, which need a Sorter.
+func SortInts(s []int, ordering Ordering) {
+	switch ordering {
+	case Ascending:
+		sort.Sort(sort.IntSlice(s))
+	case Descending:
+		sort.Sort(sort.Reverse(sort.IntSlice(s)))
+	case DescendingStableTies:
+		sort.Stable(sort.Reverse(sort.IntSlice(s)))
+	default:
+		panic(fmt.Sprintf("Invalid ordering %v for ints", ordering))
+	}
+}
+
+// Reverse a []int.
+func ReverseInts(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Reverse a []string.
+func ReverseStrings(s []string) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Reverse a []float64.
+func ReverseFloats(s []float64) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+type stringsInsensitive []string
+
+func (s stringsInsensitive) Len() int      { return len(s) }
+func (s stringsInsensitive) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s stringsInsensitive) Less(i, j int) bool {
+	return compareFoldString(s[i], s[j]) < 0
+}
@@ -0,0 +1,156 @@
+package sortutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Checks whether a slice can be sorted using a Getter in the order specified
+// by Ordering, without modifying it, and returns an error describing the
+// first problem found, if any: the slice isn't a slice or a pointer to an
+// array, getter panics or returns the wrong number of values, the values
+// can't be compared, or the ordering doesn't apply to them, e.g. because
+// it's case-insensitive and they aren't strings. This lets e.g. a UI show a
+// helpful message for a user-configured sort instead of recovering from a
+// panic. getter may be nil to check the elements themselves.
+func Validate(slice interface{}, getter Getter, ordering Ordering) (err error) {
+	if ordering < 0 || int(ordering) >= len(orderings) {
+		return fmt.Errorf("Invalid ordering %d", int(ordering))
+	}
+	s := New(slice, getter, ordering)
+	switch s.Slice.Kind() {
+	case reflect.Slice:
+	case reflect.Array:
+		if !s.Slice.CanAddr() {
+			return fmt.Errorf("Array of type %v is not addressable; pass a pointer to it", s.Slice.Type())
+		}
+	default:
+		return fmt.Errorf("Cannot sort type %T; expected a slice or a pointer to a slice or array", slice)
+	}
+	if s.Slice.Len() == 0 {
+		return nil
+	}
+	if s.Getter == nil {
+		s.Getter = SimpleGetter()
+	}
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("%v", x)
+		}
+	}()
+	s.vals = s.Getter(s.Slice)
+	if len(s.vals) == 0 {
+		return errors.New(errNoValues)
+	}
+	if len(s.vals) != s.Slice.Len() {
+		return fmt.Errorf("Getter returned %d values for slice of length %d", len(s.vals), s.Slice.Len())
+	}
+	if s.typed() == nil {
+		return nil
+	}
+	if s.mixedIntegers() {
+		return nil
+	}
+	for i, v := range s.vals {
+		if !isNil(v) && v.Type() != s.valType {
+			return fmt.Errorf("Value at index %d has type %v, not %v like the others", i, v.Type(), s.valType)
+		}
+	}
+	return nil
+}
+
+// Like Validate, but checks sorting a slice of structs by the field with
+// name, e.g. one chosen by a user. In addition to the problems Validate
+// reports, an error is returned if the struct type doesn't have an exported
+// field with name.
+func ValidateField(slice interface{}, name string, ordering Ordering) error {
+	s := New(slice, nil, ordering)
+	if k := s.Slice.Kind(); k != reflect.Slice && k != reflect.Array {
+		return Validate(slice, nil, ordering)
+	}
+	index, err := ResolveField(s.Slice.Type().Elem(), name)
+	if err != nil {
+		return err
+	}
+	return Validate(slice, FieldByIndexGetter(index), ordering)
+}
+
+// Checks whether ordering applies to the values a slice would be sorted by
+// using a Getter, and returns an error instead of the runtime panic Sort
+// would cause if it doesn't, i.e. if ordering is case-insensitive and the
+// values aren't strings, []byte or []rune. This lets e.g. a UI that offers
+// every direction for every column reject case-insensitive sorting of
+// numbers. Unlike Validate, no other problems are checked for. getter may
+// be nil to check the elements themselves.
+func EnsureOrderingCompatible(slice interface{}, getter Getter, ordering Ordering) (err error) {
+	if o := ordering.base(); o != CaseInsensitiveAscending && o != CaseInsensitiveDescending {
+		return nil
+	}
+	s := New(slice, getter, ordering)
+	if s.Slice.Len() == 0 {
+		return nil
+	}
+	if s.Getter == nil {
+		s.Getter = SimpleGetter()
+	}
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("%v", x)
+		}
+	}()
+	s.vals = s.Getter(s.Slice)
+	if i, ok := s.first(); ok && !textual(s.vals[i].Type()) {
+		return fmt.Errorf("Invalid ordering %v for type %v: case-insensitive orderings only apply to strings, []byte and []rune", ordering, s.vals[i].Type())
+	}
+	return nil
+}
+
+// Returns the kind and type of the values that a slice would be sorted by
+// using a Getter, without sorting it, e.g. to decide whether a column
+// contains numbers, text or times. Recognized non-standard types like
+// time.Time are reported with their Kind, e.g. reflect.Struct, and can be
+// told apart by their Type. Pointers are dereferenced. An error is returned
+// if there are no non-nil values, or if they can't be sorted.
+func DetectKind(slice interface{}, getter Getter) (kind reflect.Kind, typ reflect.Type, err error) {
+	s := New(slice, getter, Ascending)
+	if s.Getter == nil {
+		s.Getter = SimpleGetter()
+	}
+	defer func() {
+		if x := recover(); x != nil {
+			kind, typ, err = reflect.Invalid, nil, fmt.Errorf("%v", x)
+		}
+	}()
+	s.vals = s.Getter(s.Slice)
+	if s.typed() == nil {
+		return reflect.Invalid, nil, fmt.Errorf("No values to detect the kind of")
+	}
+	return s.valKind, s.valType, nil
+}
+
+// Returns the indices at which the values retrieved from a slice by getter
+// differ from the values that would be at those indices if the slice were
+// sorted in the order specified by ordering, without modifying it. The
+// result is empty if the slice is already sorted. Since values are compared
+// rather than elements, the order of elements with equal values doesn't
+// matter. This is useful e.g. for tests checking that a slice stays sorted.
+// getter may be nil to compare the elements themselves.
+func DiffFromSorted(slice interface{}, getter Getter, ordering Ordering) []int {
+	s := New(slice, getter, ordering)
+	diff := []int{}
+	if s.Slice.Len() < 2 {
+		return diff
+	}
+	s.setup()
+	current := append([]reflect.Value(nil), s.vals...)
+	sort.Stable(s.lesser())
+	less := (&Sorter{Ordering: s.Ordering, Policy: s.Policy}).lessFunc(current)
+	for i, v := range current {
+		if less(v, s.vals[i]) || less(s.vals[i], v) {
+			diff = append(diff, i)
+		}
+	}
+	return diff
+}